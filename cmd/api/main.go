@@ -46,6 +46,28 @@ func main() {
 		log.Fatalf("Could not ping database: %v", err)
 	}
 
+	bus := services.NewAuctionBus(pool)
+	go bus.Run(ctx)
+
+	retraction := services.RetractionPolicy{
+		Window:        durationEnv("GOBID_RETRACTION_WINDOW", services.DefaultRetractionPolicy.Window),
+		ClosingPeriod: durationEnv("GOBID_RETRACTION_CLOSING_PERIOD", services.DefaultRetractionPolicy.ClosingPeriod),
+	}
+	increments := services.DefaultBidIncrements
+	if raw := os.Getenv("GOBID_BID_INCREMENTS"); raw != "" {
+		increments, err = services.ParseBidIncrements(raw)
+		if err != nil {
+			log.Fatalf("Invalid GOBID_BID_INCREMENTS: %q must look like 100:1,1000:5,*:10", raw)
+		}
+	}
+
+	bids := services.NewBidsService(
+		pool,
+		services.WithAuctionBus(bus),
+		services.WithRetractionPolicy(retraction),
+		services.WithBidIncrements(increments),
+	)
+
 	s := scs.New()
 	s.Store = pgxstore.New(pool)
 	s.Lifetime = 24 * time.Hour
@@ -55,22 +77,44 @@ func main() {
 	a := api.Api{
 		UserService:     services.NewUsersService(pool),
 		ProductsService: services.NewProductsService(pool),
-		BidsService:     services.NewBidsService(pool),
+		BidsService:     bids,
+		AuctionBus:      bus,
 		Router:          chi.NewMux(),
 		WsUpgrader: &websocket.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				return true
 			},
+			Subprotocols: []string{services.SubprotocolV2, services.SubprotocolV1},
 		},
 		Sessions: s,
 		AuctionLoby: services.AuctionLobby{
-			Rooms: make(map[uuid.UUID]*services.AuctionRoom),
+			Rooms:     make(map[uuid.UUID]*services.AuctionRoom),
+			Scheduled: make(map[uuid.UUID]*time.Timer),
 		},
 	}
 
 	a.BindRoutes()
+	if err := a.RestoreAuctionRooms(ctx); err != nil {
+		log.Fatalf("Could not restore auction rooms: %v", err)
+	}
+
 	fmt.Println("Server is running on port 3080")
 	if err := http.ListenAndServe("0.0.0.0:3080", a.Router); err != nil {
 		panic(err)
 	}
 }
+
+// durationEnv reads a duration such as "5m" from the environment variable name,
+// falling back when it is not set.
+func durationEnv(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		log.Fatalf("Invalid %s: %q must be a non negative duration such as 5m", name, raw)
+	}
+	return d
+}
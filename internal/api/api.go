@@ -15,5 +15,6 @@ type Api struct {
 	UserService     *services.UsersService
 	BidsService     *services.BidsService
 	ProductsService *services.ProductsService
+	AuctionBus      *services.AuctionBus
 	AuctionLoby     services.AuctionLobby
 }
@@ -7,6 +7,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 func (a *Api) handleSubscribeUserToAuction(w http.ResponseWriter, r *http.Request) {
@@ -20,26 +22,127 @@ func (a *Api) handleSubscribeUserToAuction(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	_, err = a.ProductsService.GetProductById(r.Context(), productId)
+	var lastSeq int64
+	rawLastSeq := r.URL.Query().Get("last_seq")
+	if rawLastSeq != "" {
+		lastSeq, err = strconv.ParseInt(rawLastSeq, 10, 64)
+		if err != nil || lastSeq < 0 {
+			_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+				"message": "invalid last_seq must be a non negative integer",
+			})
+			return
+		}
+	}
+
+	room, ok := a.liveAuctionRoom(w, r, productId)
+	if !ok {
+		return
+	}
+
+	// visitors without a session join as spectators
+	userid, bidder := a.Sessions.Get(r.Context(), "authUserId").(uuid.UUID)
+
+	conn, err := a.WsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+			"error": "connection error",
+		})
+		return
+	}
+
+	client := services.NewSpectator(room, conn)
+	if bidder {
+		client = services.NewClient(room, conn, userid)
+	}
+	if rawLastSeq != "" {
+		client.Resume(lastSeq)
+	}
+
+	// writing starts first so the send buffer drains while the replay fills it
+	go client.WriteEventLoop()
+
+	if !client.Join(r.Context()) {
+		return
+	}
+
+	go client.ReadEventLoop()
+}
+
+// handleAuctionEvents streams the public events of an auction as Server-Sent
+// Events, for clients that only follow it. Bidding stays on the websocket.
+func (a *Api) handleAuctionEvents(w http.ResponseWriter, r *http.Request) {
+	rawProductId := chi.URLParam(r, "product_id")
+
+	productId, err := uuid.Parse(rawProductId)
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message": "invalid product id must be a valid UUID",
+		})
+		return
+	}
+
+	var lastSeq int64
+	rawLastSeq := r.Header.Get("Last-Event-ID")
+	if rawLastSeq != "" {
+		lastSeq, err = strconv.ParseInt(rawLastSeq, 10, 64)
+		if err != nil || lastSeq < 0 {
+			_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+				"message": "invalid Last-Event-ID must be a non negative integer",
+			})
+			return
+		}
+	}
+
+	room, ok := a.liveAuctionRoom(w, r, productId)
+	if !ok {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	client := services.NewStreamClient(room)
+	if rawLastSeq != "" {
+		client.Resume(lastSeq)
+	}
+
+	// the replay of a resuming client is streamed while it joins
+	go client.Join(r.Context())
+
+	client.StreamEventLoop(r.Context(), w, rc.Flush)
+}
+
+// liveAuctionRoom finds the running room of productId, opening it when the
+// auction is live but has no room on this instance yet. It writes the error
+// response and reports false when there is no room to join.
+func (a *Api) liveAuctionRoom(w http.ResponseWriter, r *http.Request, productId uuid.UUID) (*services.AuctionRoom, bool) {
+	product, err := a.ProductsService.GetProductById(r.Context(), productId)
 	if err != nil {
 		if errors.Is(err, services.ErrProductNotFound) {
 			_ = jsonutils.EncodeJson(w, r, http.StatusNotFound, map[string]any{
 				"message": "product not found",
 			})
-			return
+			return nil, false
 		}
 		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
 			"error": "unexpected error",
 		})
-		return
+		return nil, false
 	}
 
-	userid, ok := a.Sessions.Get(r.Context(), "authUserId").(uuid.UUID)
-	if !ok {
-		_ = jsonutils.EncodeJson(w, r, http.StatusUnauthorized, map[string]any{
-			"error": "unauthorized",
+	if product.AuctionStart.After(time.Now()) {
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message":       "the auction has not started yet",
+			"auction_start": product.AuctionStart,
 		})
-		return
+		return nil, false
 	}
 
 	a.AuctionLoby.Lock()
@@ -47,24 +150,32 @@ func (a *Api) handleSubscribeUserToAuction(w http.ResponseWriter, r *http.Reques
 	a.AuctionLoby.Unlock()
 
 	if !ok {
-		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
-			"message": "the auction has ended",
-		})
-		return
+		// the auction may have been created on another instance
+		ok, err = a.ProductsService.IsAuctionOpen(r.Context(), productId)
+		if err != nil {
+			_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+				"error": "unexpected error",
+			})
+			return nil, false
+		}
+		if ok {
+			room = a.openAuctionRoom(product)
+		}
 	}
 
-	conn, err := a.WsUpgrader.Upgrade(w, r, nil)
-	if err != nil {
-		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
-			"error": "connection error",
+	if !ok {
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message": "the auction has ended",
 		})
-		return
+		return nil, false
 	}
 
-	client := services.NewClient(room, conn, userid)
-
-	room.Register <- client
+	return room, true
+}
 
-	go client.ReadEventLoop()
-	go client.WriteEventLoop()
+// handleProtocolSchema serves the JSON Schema of the v2 websocket messages.
+func (a *Api) handleProtocolSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(services.ProtocolV2Schema)
 }
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"github.com/JoaoRafa19/gobid/internal/services"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"log/slog"
+	"time"
+)
+
+// scheduleAuctionRoom opens the room of product once its auction starts.
+func (a *Api) scheduleAuctionRoom(product pgstore.Product) {
+	if wait := time.Until(product.AuctionStart); wait > 0 {
+		a.AuctionLoby.Lock()
+		a.AuctionLoby.Scheduled[product.ID] = time.AfterFunc(wait, func() {
+			a.AuctionLoby.Lock()
+			delete(a.AuctionLoby.Scheduled, product.ID)
+			a.AuctionLoby.Unlock()
+
+			// another instance may have cancelled it in the meantime
+			open, err := a.ProductsService.IsAuctionOpen(context.Background(), product.ID)
+			if err != nil {
+				slog.Error("failed to check scheduled auction", "Auction ID", product.ID, "error", err)
+			}
+			if err == nil && !open {
+				return
+			}
+
+			a.openAuctionRoom(product)
+		})
+		a.AuctionLoby.Unlock()
+		return
+	}
+
+	a.openAuctionRoom(product)
+}
+
+// openAuctionRoom starts the room of product, unless it is already running.
+func (a *Api) openAuctionRoom(product pgstore.Product) *services.AuctionRoom {
+	a.AuctionLoby.Lock()
+	if room, ok := a.AuctionLoby.Rooms[product.ID]; ok {
+		a.AuctionLoby.Unlock()
+		return room
+	}
+	auctionRoom := services.NewAuctionRoom(context.Background(), product, a.BidsService, a.AuctionBus)
+	a.AuctionLoby.Rooms[product.ID] = auctionRoom
+	a.AuctionLoby.Unlock()
+
+	go func() {
+		auctionRoom.Start()
+
+		a.AuctionLoby.Lock()
+		delete(a.AuctionLoby.Rooms, product.ID)
+		a.AuctionLoby.Unlock()
+	}()
+
+	return auctionRoom
+}
+
+// RestoreAuctionRooms reopens a room for every auction that is still running,
+// so a restart does not end the auctions kept only in memory. Auctions that
+// ended without being settled get a room too, its deadline has passed so it
+// settles them right away.
+func (a *Api) RestoreAuctionRooms(ctx context.Context) error {
+	products, err := a.ProductsService.ListOpenAuctions(ctx)
+	if err != nil {
+		return err
+	}
+
+	unsettled, err := a.ProductsService.ListUnsettledAuctions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range products {
+		a.scheduleAuctionRoom(p)
+	}
+	for _, p := range unsettled {
+		a.openAuctionRoom(p)
+	}
+
+	slog.Info("Auction rooms restored", "count", len(products), "unsettled", len(unsettled))
+	return nil
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"errors"
+	"github.com/JoaoRafa19/gobid/internal/jsonutils"
+	"github.com/JoaoRafa19/gobid/internal/services"
+	"github.com/JoaoRafa19/gobid/internal/usecase/product"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// handlePlaceBid places a bid through the auction room, like a websocket
+// PlaceBid message, and answers with its outcome.
+func (a *Api) handlePlaceBid(w http.ResponseWriter, r *http.Request) {
+	productId, err := uuid.Parse(chi.URLParam(r, "product_id"))
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message": "invalid product id must be a valid UUID",
+		})
+		return
+	}
+
+	data, problems, err := jsonutils.DecodeValidJson[product.PlaceBidRequest](r)
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusUnprocessableEntity, problems)
+		return
+	}
+
+	userId, ok := a.Sessions.Get(r.Context(), "authUserId").(uuid.UUID)
+	if !ok {
+		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+			"error": "unexpected error",
+		})
+		return
+	}
+
+	room, ok := a.liveAuctionRoom(w, r, productId)
+	if !ok {
+		return
+	}
+
+	// the room orders the bid among the websocket ones and tells its clients
+	replies := make(chan services.Message, 1)
+	request := services.Message{
+		Kind:      services.PlaceBid,
+		UserId:    userId,
+		Amount:    data.Amount,
+		MaxAmount: data.MaxAmount,
+		Currency:  data.Currency,
+		Reply:     replies,
+	}
+
+	select {
+	case room.Broadcast <- request:
+	case <-room.Done():
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message": "the auction has ended",
+		})
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	var reply services.Message
+	select {
+	case reply = <-replies:
+	case <-room.Done():
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message": "the auction has ended",
+		})
+		return
+	case <-r.Context().Done():
+		return
+	}
+
+	if reply.Kind != services.SuccessfullyPlacedBid {
+		switch {
+		case errors.Is(reply.Err, services.ErrAuctionClosed):
+			_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+				"message": "the auction has ended",
+			})
+		case errors.Is(reply.Err, services.ErrAuctionNotStarted):
+			_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+				"message": "the auction has not started yet",
+			})
+		case errors.Is(reply.Err, services.ErrBidIsTooLow),
+			errors.Is(reply.Err, services.ErrBidBelowIncrement),
+			errors.Is(reply.Err, services.ErrInvalidMaxBid),
+			errors.Is(reply.Err, services.ErrCurrencyMismatch),
+			errors.Is(reply.Err, services.ErrUnsupportedAuctionType):
+			_ = jsonutils.EncodeJson(w, r, http.StatusUnprocessableEntity, map[string]any{
+				"error": reply.Err.Error(),
+			})
+		default:
+			_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+				"error": "failed to place bid",
+			})
+		}
+		return
+	}
+
+	_ = jsonutils.EncodeJson(w, r, http.StatusCreated, map[string]any{
+		"message":      reply.Message,
+		"amount":       data.Amount,
+		"currency":     reply.Currency,
+		"min_next_bid": reply.MinNextBid,
+		"reserve_met":  reply.ReserveMet,
+	})
+}
@@ -1,12 +1,14 @@
 package api
 
 import (
-	"context"
+	"errors"
 	"github.com/JoaoRafa19/gobid/internal/jsonutils"
 	"github.com/JoaoRafa19/gobid/internal/services"
 	"github.com/JoaoRafa19/gobid/internal/usecase/product"
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"net/http"
+	"time"
 )
 
 func (a *Api) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
@@ -24,14 +26,7 @@ func (a *Api) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	productId, err := a.ProductsService.CreateProduct(
-		r.Context(),
-		userId,
-		data.ProductName,
-		data.Description,
-		data.BasePrice,
-		data.AuctionEnd,
-	)
+	created, err := a.ProductsService.CreateProduct(r.Context(), userId, data)
 
 	if err != nil {
 		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
@@ -40,19 +35,142 @@ func (a *Api) handleCreateProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ctx, _ := context.WithDeadline(context.Background(), data.AuctionEnd)
+	a.scheduleAuctionRoom(created)
 
-	auctionRoom := services.NewAuctionRoom(ctx, productId, a.BidsService)
+	message := "auction has started successfully"
+	if created.AuctionStart.After(time.Now()) {
+		message = "auction has been scheduled successfully"
+	}
+
+	_ = jsonutils.EncodeJson(w, r, http.StatusCreated, map[string]any{
+		"message":       message,
+		"product":       created.ID,
+		"auction_start": created.AuctionStart,
+		"currency":      created.Currency,
+	})
+
+}
+
+func (a *Api) handleGetProduct(w http.ResponseWriter, r *http.Request) {
+	productId, err := uuid.Parse(chi.URLParam(r, "product_id"))
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message": "invalid product id must be a valid UUID",
+		})
+		return
+	}
+
+	found, err := a.ProductsService.GetProductById(r.Context(), productId)
+	if err != nil {
+		if errors.Is(err, services.ErrProductNotFound) {
+			_ = jsonutils.EncodeJson(w, r, http.StatusNotFound, map[string]any{
+				"message": "product not found",
+			})
+			return
+		}
+		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+			"error": "unexpected error",
+		})
+		return
+	}
+
+	price, err := a.ProductsService.CurrentPrice(r.Context(), found)
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+			"error": "unexpected error",
+		})
+		return
+	}
+
+	referencePrices, err := a.ProductsService.ReferencePrices(r.Context(), price, found.Currency)
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+			"error": "unexpected error",
+		})
+		return
+	}
+
+	_ = jsonutils.EncodeJson(w, r, http.StatusOK, map[string]any{
+		"id":               found.ID,
+		"seller_id":        found.SellerID,
+		"product_name":     found.ProductName,
+		"description":      found.Description,
+		"auction_type":     found.AuctionType,
+		"auction_start":    found.AuctionStart,
+		"auction_end":      found.AuctionEnd,
+		"sold":             found.Sold,
+		"currency":         found.Currency,
+		"base_price":       found.BasePrice,
+		"buy_now_price":    found.BuyNowPrice,
+		"current_price":    price,
+		"reference_prices": referencePrices,
+	})
+}
+
+func (a *Api) handleCancelAuction(w http.ResponseWriter, r *http.Request) {
+	productId, err := uuid.Parse(chi.URLParam(r, "product_id"))
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+			"message": "invalid product id must be a valid UUID",
+		})
+		return
+	}
+
+	data, problems, err := jsonutils.DecodeValidJson[product.CancelAuctionRequest](r)
+	if err != nil {
+		_ = jsonutils.EncodeJson(w, r, http.StatusUnprocessableEntity, problems)
+		return
+	}
 
-	go auctionRoom.Start()
+	userId, ok := a.Sessions.Get(r.Context(), "authUserId").(uuid.UUID)
+	if !ok {
+		_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+			"error": "unexpected error",
+		})
+		return
+	}
+
+	_, err = a.ProductsService.CancelAuction(r.Context(), productId, userId, data.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrProductNotFound):
+			_ = jsonutils.EncodeJson(w, r, http.StatusNotFound, map[string]any{
+				"message": "product not found",
+			})
+		case errors.Is(err, services.ErrNotProductSeller):
+			_ = jsonutils.EncodeJson(w, r, http.StatusForbidden, map[string]any{
+				"error": err.Error(),
+			})
+		case errors.Is(err, services.ErrAuctionClosed):
+			_ = jsonutils.EncodeJson(w, r, http.StatusBadRequest, map[string]any{
+				"message": "the auction has ended",
+			})
+		default:
+			_ = jsonutils.EncodeJson(w, r, http.StatusInternalServerError, map[string]any{
+				"error": "failed to cancel product auction",
+			})
+		}
+		return
+	}
 
 	a.AuctionLoby.Lock()
-	a.AuctionLoby.Rooms[productId] = auctionRoom
+	if timer, ok := a.AuctionLoby.Scheduled[productId]; ok {
+		timer.Stop()
+		delete(a.AuctionLoby.Scheduled, productId)
+	}
+	room, ok := a.AuctionLoby.Rooms[productId]
 	a.AuctionLoby.Unlock()
 
-	_ = jsonutils.EncodeJson(w, r, http.StatusCreated, map[string]any{
-		"message": "auction has started successfully",
-		"product": productId,
+	// rooms of this auction on every instance stop on the cancelled event
+	err = a.AuctionBus.Publish(r.Context(), productId, services.Message{
+		Kind:    services.AuctionCancelled,
+		Message: data.Reason,
 	})
+	if err != nil && ok {
+		room.Cancel(data.Reason)
+	}
 
+	_ = jsonutils.EncodeJson(w, r, http.StatusOK, map[string]any{
+		"message": "auction has been cancelled",
+	})
 }
@@ -18,6 +18,7 @@ func (a *Api) BindRoutes() {
 	a.Router.Route("/api", func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
 			r.Get("/csrftoken", a.HandleCSRFToken)
+			r.Get("/protocol/gobid.v2.schema.json", a.handleProtocolSchema)
 			r.Route("/users", func(r chi.Router) {
 				r.Post("/signup", a.handleSignupUser)
 				r.Post("/login", a.handleLoginUser)
@@ -27,10 +28,14 @@ func (a *Api) BindRoutes() {
 				})
 			})
 			r.Route("/products", func(r chi.Router) {
+				r.Get("/ws/subscribe/{product_id}", a.handleSubscribeUserToAuction)
+				r.Get("/{product_id}/events", a.handleAuctionEvents)
 				r.Group(func(r chi.Router) {
 					r.Use(a.AuthMiddleware)
 					r.Post("/", a.handleCreateProduct)
-					r.Get("/ws/subscribe/{product_id}", a.handleSubscribeUserToAuction)
+					r.Get("/{product_id}", a.handleGetProduct)
+					r.Post("/{product_id}/cancel", a.handleCancelAuction)
+					r.Post("/{product_id}/bids", a.handlePlaceBid)
 				})
 			})
 		})
@@ -0,0 +1,89 @@
+// Package money stores amounts as integer minor units (cents), so prices are
+// compared and added exactly instead of going through floating point.
+package money
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Amount is a quantity of money in minor units: Amount(1050) is 10.50.
+type Amount int64
+
+// Decimals is the number of minor unit digits of an Amount.
+const Decimals = 2
+
+// Unit is one major unit of money, Amount(100).
+const Unit Amount = 100
+
+var ErrInvalidAmount = errors.New("invalid money amount")
+
+// Parse reads a decimal amount such as "10", "10.5" or "-10.50". Amounts with
+// more than Decimals fractional digits are rejected rather than rounded.
+func Parse(s string) (Amount, error) {
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+
+	whole, fraction, _ := strings.Cut(s, ".")
+	if whole == "" || len(fraction) > Decimals || !digits(whole) || !digits(fraction) {
+		return 0, ErrInvalidAmount
+	}
+
+	fraction += strings.Repeat("0", Decimals-len(fraction))
+	units, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+
+	if negative {
+		units = -units
+	}
+	return Amount(units), nil
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats a as a decimal with exactly Decimals fractional digits.
+func (a Amount) String() string {
+	sign := ""
+	units := int64(a)
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	s := strconv.FormatInt(units, 10)
+	if len(s) <= Decimals {
+		s = strings.Repeat("0", Decimals-len(s)+1) + s
+	}
+	return sign + s[:len(s)-Decimals] + "." + s[len(s)-Decimals:]
+}
+
+// MarshalJSON writes a as a JSON number carrying its exact decimal digits.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// UnmarshalJSON accepts both a JSON number and a quoted decimal string.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+
+	parsed, err := Parse(strings.Trim(s, `"`))
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
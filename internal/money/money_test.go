@@ -0,0 +1,100 @@
+package money
+
+import (
+	"testing"
+)
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		json    string
+		want    Amount
+		wantErr bool
+	}{
+		{json: `"10"`, want: 1000},
+		{json: `10`, want: 1000},
+		{json: `10.5`, want: 1050},
+		{json: `"10.50"`, want: 1050},
+		{json: `-0.05`, want: -5},
+		{json: `0.001`, wantErr: true},
+		{json: `1e2`, wantErr: true},
+		{json: `.5`, wantErr: true},
+		{json: `"abc"`, wantErr: true},
+		{json: `""`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			var got Amount
+			err := got.UnmarshalJSON([]byte(tt.json))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("UnmarshalJSON(%s) = %d, want error", tt.json, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalJSON(%s) error: %v", tt.json, err)
+			}
+			if got != tt.want {
+				t.Errorf("UnmarshalJSON(%s) = %d, want %d", tt.json, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "10", want: 1000},
+		{in: "10.5", want: 1050},
+		{in: "10.50", want: 1050},
+		{in: "-0.05", want: -5},
+		{in: "0.001", wantErr: true},
+		{in: "1e2", wantErr: true},
+		{in: ".5", wantErr: true},
+		{in: "-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %d, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{in: 5, want: "0.05"},
+		{in: 50, want: "0.50"},
+		{in: 0, want: "0.00"},
+		{in: 1050, want: "10.50"},
+		{in: -5, want: "-0.05"},
+		{in: 100 * Unit, want: "100.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.in.String(); got != tt.want {
+				t.Errorf("Amount(%d).String() = %q, want %q", int64(tt.in), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	auctionChannelPrefix = "auction_"
+	ListenRetryDelay     = time.Second * 2
+)
+
+// AuctionBus carries room events between API instances through Postgres
+// NOTIFY. Every event is published on the channel of its auction and a single
+// connection per instance listens on the channels of the rooms it runs, so a
+// room reaches the clients connected to any instance.
+type AuctionBus struct {
+	pool    *pgxpool.Pool
+	queries *pgstore.Queries
+
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]subscriber
+	dirty       bool
+	wake        context.CancelFunc
+}
+
+type subscriber struct {
+	events chan<- Message
+	resync chan<- struct{}
+	done   <-chan struct{}
+}
+
+func NewAuctionBus(pool *pgxpool.Pool) *AuctionBus {
+	return &AuctionBus{
+		pool:        pool,
+		queries:     pgstore.New(pool),
+		subscribers: make(map[uuid.UUID]subscriber),
+	}
+}
+
+func auctionChannel(auctionId uuid.UUID) string {
+	return auctionChannelPrefix + hex.EncodeToString(auctionId[:])
+}
+
+// Publish stores m as the next event of auctionId and sends it to the rooms of
+// the auction on every instance, this one included.
+func (b *AuctionBus) Publish(ctx context.Context, auctionId uuid.UUID, m Message) error {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := b.append(ctx, b.queries.WithTx(tx), auctionId, m); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// append stores m as the next event of auctionId and notifies it within the
+// transaction of queries, returning m with its sequence number. The sequence
+// counter stays locked until the transaction ends, so events are numbered and
+// arrive in the order their transactions commit. Changes to an auction append
+// their events in the same transaction, which keeps that order the order of
+// the changes.
+func (b *AuctionBus) append(ctx context.Context, queries *pgstore.Queries, auctionId uuid.UUID, m Message) (Message, error) {
+	m.Seq = 0
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return Message{}, err
+	}
+
+	m.Seq, err = queries.AppendAuctionEvent(ctx, pgstore.AppendAuctionEventParams{
+		ID:      auctionId,
+		Payload: payload,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	payload, err = json.Marshal(m)
+	if err != nil {
+		return Message{}, err
+	}
+
+	err = queries.NotifyAuctionEvent(ctx, pgstore.NotifyAuctionEventParams{
+		Channel: auctionChannel(auctionId),
+		Payload: string(payload),
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	return m, nil
+}
+
+// EventsAfter lists the stored events of auctionId that came after seq.
+func (b *AuctionBus) EventsAfter(ctx context.Context, auctionId uuid.UUID, seq int64) ([]Message, error) {
+	rows, err := b.queries.ListAuctionEventsAfter(ctx, pgstore.ListAuctionEventsAfterParams{
+		ProductID: auctionId,
+		Seq:       seq,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Message, 0, len(rows))
+	for _, row := range rows {
+		var m Message
+		if err := json.Unmarshal(row.Payload, &m); err != nil {
+			return nil, err
+		}
+		m.Seq = row.Seq
+		events = append(events, m)
+	}
+
+	return events, nil
+}
+
+// subscribe delivers the events of auctionId to events until done is closed.
+// Events published while the channel was not listened to are not delivered,
+// so resync is signaled every time listening starts and the subscriber reads
+// what it missed from the stored events.
+func (b *AuctionBus) subscribe(auctionId uuid.UUID, events chan<- Message, resync chan<- struct{}, done <-chan struct{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers[auctionId] = subscriber{events: events, resync: resync, done: done}
+	b.changed()
+}
+
+func (b *AuctionBus) unsubscribe(auctionId uuid.UUID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.subscribers, auctionId)
+	b.changed()
+}
+
+// changed interrupts the listener so it updates its channels, b.mu must be held.
+func (b *AuctionBus) changed() {
+	b.dirty = true
+	if b.wake != nil {
+		b.wake()
+	}
+}
+
+// Run listens for auction events until ctx is done, reconnecting whenever the
+// listening connection fails.
+func (b *AuctionBus) Run(ctx context.Context) {
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Error("auction bus listener stopped", "error", err)
+		select {
+		case <-time.After(ListenRetryDelay):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *AuctionBus) listen(ctx context.Context) error {
+	pooled, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// a connection left listening must not go back to the pool
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	listening := make(map[string]bool)
+	for {
+		started, err := b.syncChannels(ctx, conn, listening)
+		if err != nil {
+			return err
+		}
+		b.resync(started)
+
+		waitCtx, wake := context.WithCancel(ctx)
+		b.mu.Lock()
+		b.wake = wake
+		if b.dirty {
+			wake()
+		}
+		b.mu.Unlock()
+
+		notification, err := conn.WaitForNotification(waitCtx)
+		wake()
+		if err != nil {
+			if ctx.Err() == nil && waitCtx.Err() != nil {
+				// woken up to listen on a new channel
+				continue
+			}
+			return err
+		}
+
+		b.dispatch(notification)
+	}
+}
+
+// syncChannels makes conn listen on the channel of every subscribed auction and
+// nothing else, returning the auctions it started listening to.
+func (b *AuctionBus) syncChannels(ctx context.Context, conn *pgx.Conn, listening map[string]bool) ([]uuid.UUID, error) {
+	b.mu.Lock()
+	b.dirty = false
+	wanted := make(map[string]uuid.UUID, len(b.subscribers))
+	for auctionId := range b.subscribers {
+		wanted[auctionChannel(auctionId)] = auctionId
+	}
+	b.mu.Unlock()
+
+	var started []uuid.UUID
+	for channel, auctionId := range wanted {
+		if listening[channel] {
+			continue
+		}
+		if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return nil, err
+		}
+		listening[channel] = true
+		started = append(started, auctionId)
+	}
+
+	for channel := range listening {
+		if _, ok := wanted[channel]; ok {
+			continue
+		}
+		if _, err := conn.Exec(ctx, "UNLISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return nil, err
+		}
+		delete(listening, channel)
+	}
+
+	return started, nil
+}
+
+// resync tells the subscribers of auctionIds to catch up on stored events.
+func (b *AuctionBus) resync(auctionIds []uuid.UUID) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, auctionId := range auctionIds {
+		sub, ok := b.subscribers[auctionId]
+		if !ok {
+			continue
+		}
+		select {
+		case sub.resync <- struct{}{}:
+		default:
+			// a resync is already pending
+		}
+	}
+}
+
+func (b *AuctionBus) dispatch(notification *pgconn.Notification) {
+	auctionId, err := auctionIdFromChannel(notification.Channel)
+	if err != nil {
+		slog.Error("unexpected auction channel", "channel", notification.Channel)
+		return
+	}
+
+	var m Message
+	if err := json.Unmarshal([]byte(notification.Payload), &m); err != nil {
+		slog.Error("invalid auction event", "channel", notification.Channel, "error", err)
+		return
+	}
+
+	b.mu.Lock()
+	sub, ok := b.subscribers[auctionId]
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case sub.events <- m:
+	case <-sub.done:
+	}
+}
+
+func auctionIdFromChannel(channel string) (uuid.UUID, error) {
+	raw, ok := strings.CutPrefix(channel, auctionChannelPrefix)
+	if !ok {
+		return uuid.UUID{}, errors.New("not an auction channel")
+	}
+
+	id, err := hex.DecodeString(raw)
+	if err != nil {
+		return uuid.UUID{}, err
+	}
+	return uuid.FromBytes(id)
+}
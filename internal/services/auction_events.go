@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"io"
+	"time"
+)
+
+const HeartbeatPeriod = time.Second * 15
+
+// NewStreamClient creates a read only client that follows the room through
+// StreamEventLoop instead of a websocket.
+func NewStreamClient(room *AuctionRoom) *Client {
+	return &Client{
+		Id:        uuid.New(),
+		Send:      make(chan Message, 512),
+		Room:      room,
+		Protocol:  protocolV2{},
+		Spectator: true,
+		stopped:   make(chan struct{}),
+	}
+}
+
+// StreamEventLoop writes the room events to w as Server-Sent Events until ctx
+// is done or the room stops sending, flushing after every write. Each event is
+// named by its v2 type and, when it has one, identified by its sequence number
+// so the stream can be resumed through Last-Event-ID.
+func (c *Client) StreamEventLoop(ctx context.Context, w io.Writer, flush func() error) {
+	t := time.NewTicker(HeartbeatPeriod)
+	defer func() {
+		t.Stop()
+		close(c.stopped)
+		c.unregister()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.Send:
+			if !ok {
+				return
+			}
+
+			if err := c.writeEvent(w, message); err != nil {
+				return
+			}
+			if err := flush(); err != nil {
+				return
+			}
+
+			if message.Kind == AuctionFinished || message.Kind == AuctionCancelled {
+				return
+			}
+
+		case <-t.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			if err := flush(); err != nil {
+				return
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *Client) writeEvent(w io.Writer, m Message) error {
+	data, err := c.Protocol.Encode(m)
+	if err != nil {
+		return err
+	}
+
+	if m.Seq != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", m.Seq); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", m.Kind, data)
+	return err
+}
@@ -3,13 +3,19 @@ package services
 import (
 	"context"
 	"errors"
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/jackc/pgx/v5"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var ErrSpectatorCannotBid = errors.New("spectators can not bid, log in to take part in the auction")
+
 type Kind uint8
 
 const (
@@ -27,32 +33,113 @@ const (
 	// FailedToPlaceBid Errors
 	FailedToPlaceBid
 	InvalidBody
+
+	// AuctionSettled Info, appended so the existing kinds keep their values
+	AuctionSettled
+	AuctionExtended
+	BuyNow
+	BoughtNow
+	AcceptPrice
+	PriceDropped
+	AuctionCancelled
+	RetractBid
+	BidRetracted
+	PriceCorrected
+	Snapshot
+	Tick
+	TimeSync
+	TimeSynced
 )
 
 type Message struct {
-	Message string    `json:"message,omitempty"`
-	Amount  float64   `json:"amount,omitempty"`
-	Kind    Kind      `json:"kind"`
-	UserId  uuid.UUID `json:"user_id,omitempty"`
+	Message    string       `json:"message,omitempty"`
+	Amount     money.Amount `json:"amount,omitempty"`
+	MaxAmount  money.Amount `json:"max_amount,omitempty"`
+	Kind       Kind         `json:"kind"`
+	UserId     uuid.UUID    `json:"user_id,omitempty"`
+	AuctionEnd *time.Time   `json:"auction_end,omitempty"`
+	ReserveMet *bool        `json:"reserve_met,omitempty"`
+	MinNextBid money.Amount `json:"min_next_bid,omitempty"`
+	Currency   string       `json:"currency,omitempty"`
+
+	// ServerTime stamps ticks and time sync replies, which echo the
+	// ClientTime of the request so clients can estimate clock offset and
+	// latency.
+	ServerTime *time.Time `json:"server_time,omitempty"`
+	ClientTime *time.Time `json:"client_time,omitempty"`
+
+	// Seq orders the events published for the whole room, personal and
+	// local messages have none.
+	Seq int64 `json:"seq,omitempty"`
+
+	Snapshot *AuctionSnapshot `json:"snapshot,omitempty"`
+
+	// RequestId is only carried by the v2 protocol.
+	RequestId string `json:"-"`
+	// ClientId is the connection a request came from.
+	ClientId uuid.UUID `json:"-"`
+
+	// Reply receives the outcome of a request sent to the room from outside
+	// a websocket, Err carries the failure behind a FailedToPlaceBid reply.
+	Reply chan<- Message `json:"-"`
+	Err   error          `json:"-"`
 }
 
 type Client struct {
-	Conn   *websocket.Conn
-	UserId uuid.UUID
-	Send   chan Message
-	Room   *AuctionRoom
+	// Id tells apart the connections of a user, who may join the same room
+	// from several tabs or devices.
+	Id       uuid.UUID
+	Conn     *websocket.Conn
+	UserId   uuid.UUID
+	Send     chan Message
+	Room     *AuctionRoom
+	Protocol Protocol
+
+	// Spectator clients are anonymous, they follow the public events of the
+	// room but can not bid.
+	Spectator bool
+
+	// lastSeq is the last room event the client has seen, events up to it
+	// are not sent again.
+	lastSeq int64
+	resume  bool
+
+	// closeCode and closeReason are set by the room before it closes Send on
+	// a client it dropped.
+	closeCode   int
+	closeReason string
+
+	// stopped is closed once the client stops writing.
+	stopped chan struct{}
 }
 
 const (
-	MaxMessageSize    = 512
-	ReadDeadLine      = time.Second * 20
-	WriteWaitDeadline = time.Second * 10
-	PingPeriod        = (ReadDeadLine * 9) / 10
+	MaxMessageSize       = 512
+	ReadDeadLine         = time.Second * 20
+	WriteWaitDeadline    = time.Second * 10
+	PingPeriod           = (ReadDeadLine * 9) / 10
+	SettlementTimeout    = time.Second * 10
+	TickPeriod           = time.Second * 5
+	SettlementRetryDelay = time.Second * 5
 )
 
+func (c *Client) unregister() {
+	select {
+	case c.Room.Unregister <- c:
+	case <-c.Room.Done():
+	}
+}
+
+func (c *Client) broadcast(m Message) {
+	select {
+	case c.Room.Broadcast <- m:
+	case <-c.Room.Done():
+	}
+}
+
 func (c *Client) ReadEventLoop() {
 	defer func() {
-		c.Room.Unregister <- c
+		c.unregister()
 		if err := c.Conn.Close(); err != nil {
 			slog.Info("close connection error", "error", err)
 		}
@@ -66,21 +153,28 @@ func (c *Client) ReadEventLoop() {
 	})
 
 	for {
-		var m Message
-		m.UserId = c.UserId
-		if err := c.Conn.ReadJSON(&m); err != nil {
+		_, payload, err := c.Conn.ReadMessage()
+		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				slog.Error("Unexpected close error", "error", err)
-				return
-			}
-			c.Room.Broadcast <- Message{
-				Message: "this message is invalid",
-				UserId:  c.UserId,
-				Kind:    InvalidBody,
 			}
+			return
+		}
+
+		m, err := c.Protocol.Decode(payload)
+		if err != nil || !isRequest(m.Kind) {
+			c.broadcast(Message{
+				Message:   "this message is invalid",
+				UserId:    c.UserId,
+				Kind:      InvalidBody,
+				RequestId: m.RequestId,
+				ClientId:  c.Id,
+			})
 			continue
 		}
-		c.Room.Broadcast <- m
+		m.UserId = c.UserId
+		m.ClientId = c.Id
+		c.broadcast(m)
 	}
 }
 
@@ -89,27 +183,48 @@ func (c *Client) WriteEventLoop() {
 	defer func() {
 		t.Stop()
 		_ = c.Conn.Close()
+		close(c.stopped)
 	}()
 
 	for {
 		select {
 		case message, ok := <-c.Send:
 			if !ok {
-				c.Conn.WriteJSON(Message{
-					Kind:    websocket.CloseMessage,
-					Message: "closing connection",
-				})
+				if c.closeCode != 0 {
+					c.Conn.WriteMessage(
+						websocket.CloseMessage,
+						websocket.FormatCloseMessage(c.closeCode, c.closeReason),
+					)
+					return
+				}
+				if _, v1 := c.Protocol.(protocolV1); v1 {
+					c.Conn.WriteJSON(Message{
+						Kind:    websocket.CloseMessage,
+						Message: "closing connection",
+					})
+					return
+				}
+				c.Conn.WriteMessage(
+					websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseNormalClosure, "closing connection"),
+				)
 				return
 			}
 
 			if message.Kind == AuctionFinished {
-				close(c.Send)
-				return
+				// the room closes Send next, which closes the connection
+				continue
+			}
+
+			payload, err := c.Protocol.Encode(message)
+			if err != nil {
+				slog.Error("encode message error", "error", err)
+				continue
 			}
 
 			c.Conn.SetWriteDeadline(time.Now().Add(WriteWaitDeadline))
-			if err := c.Conn.WriteJSON(message); err != nil {
-				c.Room.Unregister <- c
+			if err := c.Conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				c.unregister()
 				return
 			}
 
@@ -124,115 +239,666 @@ func (c *Client) WriteEventLoop() {
 	}
 }
 
+// Join registers the client in its room and reports whether it did. A resuming
+// client first gets the stored events it missed, waiting for its writer to
+// take them in, so a long replay does not overflow Send and get the client
+// dropped by the room. Send is closed when the client does not join, the
+// writer of the client must be running.
+func (c *Client) Join(ctx context.Context) bool {
+	if c.resume && !c.replay(ctx) {
+		close(c.Send)
+		return false
+	}
+
+	select {
+	case c.Room.Register <- c:
+		return true
+	case <-c.Room.Done():
+	case <-ctx.Done():
+	case <-c.stopped:
+	}
+	close(c.Send)
+	return false
+}
+
+// replay sends the client the stored events after lastSeq, before it joins
+// the room. The room replays the few events published meanwhile on register.
+func (c *Client) replay(ctx context.Context) bool {
+	events, err := c.Room.Bus.EventsAfter(ctx, c.Room.Id, c.lastSeq)
+	if err != nil {
+		slog.Error("failed to replay auction events", "Auction ID", c.Room.Id, "error", err)
+		return true
+	}
+
+	for _, m := range events {
+		select {
+		case c.Send <- m:
+			c.lastSeq = m.Seq
+		case <-c.Room.Done():
+			return false
+		case <-ctx.Done():
+			return false
+		case <-c.stopped:
+			return false
+		}
+	}
+	return true
+}
+
+// Resume makes the room replay every event after lastSeq to the client once it
+// registers, so a reconnecting client catches up on what it missed.
+func (c *Client) Resume(lastSeq int64) {
+	c.lastSeq = lastSeq
+	c.resume = true
+}
+
 func NewClient(room *AuctionRoom, conn *websocket.Conn, userId uuid.UUID) *Client {
 	return &Client{
-		Conn:   conn,
-		UserId: userId,
-		Send:   make(chan Message, 512),
-		Room:   room,
+		Id:       uuid.New(),
+		Conn:     conn,
+		UserId:   userId,
+		Send:     make(chan Message, 512),
+		Room:     room,
+		Protocol: ProtocolFor(conn.Subprotocol()),
+		stopped:  make(chan struct{}),
 	}
 }
 
+// NewSpectator creates an anonymous, read only client.
+func NewSpectator(room *AuctionRoom, conn *websocket.Conn) *Client {
+	c := NewClient(room, conn, uuid.Nil)
+	c.Spectator = true
+	return c
+}
+
 type AuctionRoom struct {
 	Id         uuid.UUID
 	Context    context.Context
+	AuctionEnd time.Time
+	// Clients holds the connections of the room by Client.Id.
 	Clients    map[uuid.UUID]*Client
 	Register   chan *Client
 	Unregister chan *Client
 	Broadcast  chan Message
 
 	BidsService *BidsService
+	Bus         *AuctionBus
+
+	product    pgstore.Product
+	events     chan Message
+	resync     chan struct{}
+	lastSeq    int64
+	bidders    int
+	spectators int
+	cancel     context.CancelFunc
+	deadline   *time.Timer
+	priceDrop  *time.Timer
+	done       chan struct{}
+
+	// bids caches the bidding state sent in snapshots, a bid event resets it.
+	bids *BidsState
+
+	// droppedClients counts the clients disconnected for falling behind.
+	droppedClients atomic.Int64
+
+	// cancelReason is written before cancel is called and only read once the
+	// context is done, so the context orders the access.
+	cancelReason string
 }
 
-func NewAuctionRoom(ctx context.Context, id uuid.UUID, bids *BidsService) *AuctionRoom {
+func NewAuctionRoom(ctx context.Context, product pgstore.Product, bids *BidsService, bus *AuctionBus) *AuctionRoom {
+	ctx, cancel := context.WithCancel(ctx)
 	return &AuctionRoom{
-		Id:          id,
+		Id:          product.ID,
 		Context:     ctx,
+		AuctionEnd:  product.AuctionEnd,
 		BidsService: bids,
+		Bus:         bus,
+		product:     product,
+		events:      make(chan Message, 64),
+		resync:      make(chan struct{}, 1),
+		lastSeq:     product.EventSeq,
+		cancel:      cancel,
 		Broadcast:   make(chan Message),
 		Register:    make(chan *Client),
 		Unregister:  make(chan *Client),
 		Clients:     make(map[uuid.UUID]*Client),
+		done:        make(chan struct{}),
 	}
 }
 
+// Cancel stops the room, telling every client the auction was cancelled
+// instead of finished.
+func (a *AuctionRoom) Cancel(reason string) {
+	a.cancelReason = reason
+	a.cancel()
+}
+
+// DroppedClients is the number of clients the room disconnected because they
+// did not keep up with its messages.
+func (a *AuctionRoom) DroppedClients() int64 {
+	return a.droppedClients.Load()
+}
+
+// Done is closed once the room stops running.
+func (a *AuctionRoom) Done() <-chan struct{} {
+	return a.done
+}
+
 func (a *AuctionRoom) registerClient(c *Client) {
-	slog.Info("New user connected", "Client", c)
-	a.Clients[c.UserId] = c
+	select {
+	case <-c.stopped:
+		// gone before joining, its unregister may already be handled
+		return
+	default:
+	}
+
+	a.Clients[c.Id] = c
+	if c.Spectator {
+		a.spectators++
+	} else {
+		a.bidders++
+	}
+	slog.Info("New user connected", "Client", c, "bidders", a.bidders, "spectators", a.spectators)
+
+	// a resuming client gets every event up to the snapshot first
+	if c.resume {
+		a.replay(c)
+	} else {
+		c.lastSeq = a.lastSeq
+	}
+	a.send(c, a.snapshot())
+}
+
+// replay sends c the stored events it missed since Join replayed the rest.
+// Events replayed here may also be waiting in a.events, deliver skips them once
+// they come around.
+func (a *AuctionRoom) replay(c *Client) {
+	events, err := a.Bus.EventsAfter(a.Context, a.Id, c.lastSeq)
+	if err != nil {
+		slog.Error("failed to replay auction events", "Auction ID", a.Id, "error", err)
+		return
+	}
+
+	for _, m := range events {
+		a.deliver(c, m)
+	}
+}
+
+// deliver sends m to c unless c has already seen it.
+func (a *AuctionRoom) deliver(c *Client, m Message) {
+	if m.Seq != 0 {
+		if m.Seq <= c.lastSeq {
+			return
+		}
+		c.lastSeq = m.Seq
+	}
+	a.send(c, m)
+}
+
+// send hands m to c without waiting. A client whose buffer is full is too
+// slow to follow the room, so it is dropped instead of stalling every other
+// client.
+func (a *AuctionRoom) send(c *Client, m Message) {
+	if _, ok := a.Clients[c.Id]; !ok {
+		return
+	}
+
+	select {
+	case c.Send <- m:
+	default:
+		a.drop(c)
+	}
+}
+
+// drop disconnects a lagging client, telling it why once its writer has
+// flushed what is already buffered.
+func (a *AuctionRoom) drop(c *Client) {
+	c.closeCode = websocket.CloseTryAgainLater
+	c.closeReason = "client is too slow"
+	a.disconnect(c)
+
+	dropped := a.droppedClients.Add(1)
+	slog.Warn("dropped slow client", "Auction ID", a.Id, "Client", c.Id, "dropped clients", dropped)
 }
 
 func (a *AuctionRoom) unregisterClient(c *Client) {
-	slog.Info("New user disconnected", "Client", c)
-	delete(a.Clients, c.UserId)
+	// both event loops of a client unregister it when they stop
+	if _, ok := a.Clients[c.Id]; !ok {
+		return
+	}
+
+	delete(a.Clients, c.Id)
+	if c.Spectator {
+		a.spectators--
+	} else {
+		a.bidders--
+	}
+	slog.Info("New user disconnected", "Client", c, "bidders", a.bidders, "spectators", a.spectators)
+}
+
+// disconnect removes c from the room and closes its Send, which makes its
+// writer close the connection. Only the room closes Send, so nothing is sent
+// on it afterwards.
+func (a *AuctionRoom) disconnect(c *Client) {
+	a.unregisterClient(c)
+	close(c.Send)
+}
+
+// sendToUser sends a personal message to every connection of userId.
+func (a *AuctionRoom) sendToUser(userId uuid.UUID, m Message) {
+	for _, client := range a.Clients {
+		if client.UserId == userId && !client.Spectator {
+			a.send(client, m)
+		}
+	}
 }
 
 func (a *AuctionRoom) broadcastMessage(m Message) {
 	slog.Info("New message received", "Room ID", a.Id, "Message", m, "UserID", m.UserId)
-	switch m.Kind {
-	case AuctionFinished:
-		for _, client := range a.Clients {
-			client.Send <- m
+	if a.Context.Err() != nil {
+		return
+	}
+
+	// spectators have no user, which still holds once they left the room
+	// with a request in flight
+	if isBidRequest(m.Kind) && m.UserId == uuid.Nil {
+		if client, ok := a.Clients[m.ClientId]; ok {
+			a.send(client, Message{
+				Message:   ErrSpectatorCannotBid.Error(),
+				Kind:      FailedToPlaceBid,
+				RequestId: m.RequestId,
+			})
 		}
 		return
+	}
+
+	switch m.Kind {
 	case InvalidBody:
-		client, ok := a.Clients[m.UserId]
+		client, ok := a.Clients[m.ClientId]
 		if !ok {
-			slog.Info("client not found", "user_id", m.UserId)
+			slog.Info("client not found", "user_id", m.UserId, "client_id", m.ClientId)
 			return
 		}
-		client.Send <- m
+		a.send(client, m)
 
 	case PlaceBid:
 		// place bid in product
-		bid, err := a.BidsService.PlaceBid(a.Context, a.Id, m.UserId, m.Amount)
+		placed, err := a.BidsService.PlaceBid(a.Context, a.Id, m.UserId, m.Amount, m.MaxAmount, m.Currency)
 		if err != nil {
-			if errors.Is(err, ErrBidIsTooLow) {
-				if client, ok := a.Clients[m.UserId]; ok {
-					client.Send <- Message{
-						Message: err.Error(),
-						Kind:    FailedToPlaceBid,
-						UserId:  m.UserId,
-					}
-				}
+			if errors.Is(err, ErrBidIsTooLow) ||
+				errors.Is(err, ErrBidBelowIncrement) ||
+				errors.Is(err, ErrInvalidMaxBid) ||
+				errors.Is(err, ErrAuctionClosed) ||
+				errors.Is(err, ErrAuctionNotStarted) ||
+				errors.Is(err, ErrCurrencyMismatch) ||
+				errors.Is(err, ErrUnsupportedAuctionType) {
+				a.reply(m, Message{
+					Message:   err.Error(),
+					Kind:      FailedToPlaceBid,
+					UserId:    m.UserId,
+					RequestId: m.RequestId,
+					Err:       err,
+				})
 				return
 			}
 
+			slog.Error("failed to place bid", "Auction ID", a.Id, "error", err)
+			if m.Reply != nil {
+				m.Reply <- Message{Kind: FailedToPlaceBid, UserId: m.UserId, Err: err}
+			}
+			return
+		}
+
+		if placed.Sealed {
+			a.reply(m, Message{
+				Kind:      SuccessfullyPlacedBid,
+				UserId:    m.UserId,
+				RequestId: m.RequestId,
+				Message:   "Your sealed bid has been received!",
+				Amount:    placed.Bid.Amount,
+				Currency:  a.product.Currency,
+			})
 			return
 		}
 
-		if client, ok := a.Clients[m.UserId]; ok {
-			client.Send <- Message{
-				Kind:    SuccessfullyPlacedBid,
-				UserId:  m.UserId,
-				Message: "Your bid has been placed!",
+		a.reply(m, Message{
+			Kind:       SuccessfullyPlacedBid,
+			UserId:     m.UserId,
+			RequestId:  m.RequestId,
+			Message:    "Your bid has been placed!",
+			ReserveMet: placed.ReserveMet,
+			MinNextBid: placed.MinNextBid,
+			Currency:   a.product.Currency,
+		})
+
+		// Clients on other instances may hold any user, so the new price
+		// reaches every client, the placer included.
+		a.applyEvents(placed.Events)
+
+	case BuyNow:
+		sale, err := a.BidsService.BuyNow(a.Context, a.Id, m.UserId)
+		if err != nil {
+			if errors.Is(err, ErrBuyNowUnavailable) || errors.Is(err, ErrAuctionClosed) || errors.Is(err, ErrAuctionNotStarted) {
+				a.sendToUser(m.UserId, Message{
+					Message:   err.Error(),
+					Kind:      FailedToPlaceBid,
+					UserId:    m.UserId,
+					RequestId: m.RequestId,
+				})
 			}
+			return
 		}
 
-		for id, client := range a.Clients {
-			if id == m.UserId {
-				continue
+		a.applyEvents(sale.Events)
+
+	case AcceptPrice:
+		sale, err := a.BidsService.AcceptDutchPrice(a.Context, a.Id, m.UserId)
+		if err != nil {
+			if errors.Is(err, ErrUnsupportedAuctionType) || errors.Is(err, ErrAuctionClosed) || errors.Is(err, ErrAuctionNotStarted) {
+				a.sendToUser(m.UserId, Message{
+					Message:   err.Error(),
+					Kind:      FailedToPlaceBid,
+					UserId:    m.UserId,
+					RequestId: m.RequestId,
+				})
 			}
-			newBidMessage := Message{
-				Kind:    NewBidPlaced,
-				Message: "New bid has been placed!",
-				Amount:  bid.Amount,
-				UserId:  m.UserId,
+			return
+		}
+
+		a.applyEvents(sale.Events)
+
+	case RetractBid:
+		retracted, err := a.BidsService.RetractBid(a.Context, a.Id, m.UserId)
+		if err != nil {
+			if errors.Is(err, ErrNoBidToRetract) ||
+				errors.Is(err, ErrRetractionNotAllowed) ||
+				errors.Is(err, ErrAuctionClosed) ||
+				errors.Is(err, ErrAuctionNotStarted) ||
+				errors.Is(err, ErrUnsupportedAuctionType) {
+				a.sendToUser(m.UserId, Message{
+					Message:   err.Error(),
+					Kind:      FailedToPlaceBid,
+					UserId:    m.UserId,
+					RequestId: m.RequestId,
+				})
 			}
+			return
+		}
+
+		a.sendToUser(m.UserId, Message{
+			Kind:      BidRetracted,
+			UserId:    m.UserId,
+			RequestId: m.RequestId,
+			Message:   "Your bid has been retracted",
+			Amount:    retracted.Bid.Amount,
+			Currency:  a.product.Currency,
+		})
+
+		a.applyEvents(retracted.Events)
 
-			client.Send <- newBidMessage
+	case TimeSync:
+		client, ok := a.Clients[m.ClientId]
+		if !ok {
+			return
 		}
+		now := time.Now()
+		a.send(client, Message{
+			Kind:       TimeSynced,
+			UserId:     m.UserId,
+			RequestId:  m.RequestId,
+			ServerTime: &now,
+			ClientTime: m.ClientTime,
+		})
+
 	case SuccessfullyPlacedBid:
 	case NewBidPlaced:
 	case FailedToPlaceBid:
 	}
 }
 
+// reply answers the request m on every connection of its user and to the
+// caller waiting on m.Reply, if any.
+func (a *AuctionRoom) reply(m Message, r Message) {
+	a.sendToUser(m.UserId, r)
+	if m.Reply != nil {
+		m.Reply <- r
+	}
+}
+
+// isRequest reports whether clients may send kind to the room, every other kind
+// is only sent by the room itself.
+func isRequest(kind Kind) bool {
+	return isBidRequest(kind) || kind == TimeSync
+}
+
+// isBidRequest reports whether kind asks the room to bid on the auction.
+func isBidRequest(kind Kind) bool {
+	switch kind {
+	case PlaceBid, BuyNow, AcceptPrice, RetractBid:
+		return true
+	}
+	return false
+}
+
+// applyEvents hands the events published by a change this room made to the
+// local clients without waiting for the bus, which delivers them to the other
+// instances. The copies the bus brings back are skipped by their sequence.
+func (a *AuctionRoom) applyEvents(events []Message) {
+	for _, m := range events {
+		if a.Context.Err() != nil {
+			return
+		}
+		a.handleEvent(m)
+	}
+}
+
+// handleEvent applies an event published by a room of this auction, on this
+// instance or another one, and hands it to the local clients. Events are
+// applied in sequence order: one already applied is skipped, and one that
+// skips a number makes the room catch up on the stored events, itself included.
+func (a *AuctionRoom) handleEvent(m Message) {
+	if m.Seq != 0 {
+		if m.Seq <= a.lastSeq {
+			return
+		}
+		if m.Seq > a.lastSeq+1 {
+			a.catchUp()
+			return
+		}
+	}
+	a.lastSeq = max(a.lastSeq, m.Seq)
+
+	switch m.Kind {
+	case NewBidPlaced, PriceCorrected:
+		a.bids = nil
+	case AuctionCancelled:
+		a.Cancel(m.Message)
+		return
+	case AuctionExtended:
+		if m.AuctionEnd != nil {
+			a.extend(*m.AuctionEnd)
+		}
+	}
+
+	for _, client := range a.Clients {
+		a.deliver(client, m)
+	}
+
+	if m.Kind == BoughtNow || m.Kind == AuctionSettled {
+		// the sale is already recorded, so the room closes without settling
+		a.cancel()
+	}
+}
+
+// catchUp applies the stored events the room missed while the bus was not
+// listening to its auction.
+func (a *AuctionRoom) catchUp() {
+	events, err := a.Bus.EventsAfter(a.Context, a.Id, a.lastSeq)
+	if err != nil {
+		slog.Error("failed to catch up on auction events", "Auction ID", a.Id, "error", err)
+		time.AfterFunc(ListenRetryDelay, func() {
+			select {
+			case a.resync <- struct{}{}:
+			default:
+			}
+		})
+		return
+	}
+
+	for _, m := range events {
+		if a.Context.Err() != nil {
+			return
+		}
+		a.handleEvent(m)
+	}
+}
+
+// extend moves the room deadline to auctionEnd.
+func (a *AuctionRoom) extend(auctionEnd time.Time) {
+	a.AuctionEnd = auctionEnd
+	a.deadline.Reset(time.Until(auctionEnd))
+}
+
+// tick tells the local clients the server time and the auction end, so their
+// countdowns follow the server clock. Every instance ticks its own clients, so
+// ticks are not published.
+func (a *AuctionRoom) tick() {
+	now := time.Now()
+	auctionEnd := a.AuctionEnd
+	m := Message{
+		Kind:       Tick,
+		ServerTime: &now,
+		AuctionEnd: &auctionEnd,
+	}
+	for _, client := range a.Clients {
+		a.send(client, m)
+	}
+}
+
+// dropPrice announces the current asking price of a dutch auction and
+// schedules the next drop until the floor is reached.
+func (a *AuctionRoom) dropPrice() {
+	now := time.Now()
+
+	m := Message{
+		Kind:     PriceDropped,
+		Message:  "Price has dropped",
+		Amount:   DutchPrice(a.product, now),
+		Currency: a.product.Currency,
+	}
+	for _, client := range a.Clients {
+		a.send(client, m)
+	}
+
+	if next := nextPriceDrop(a.product, now); !next.IsZero() {
+		a.priceDrop.Reset(time.Until(next))
+	}
+}
+
+// finish closes every client, announcing a cancellation when cancelReason is set.
+func (a *AuctionRoom) finish(cancelReason string) {
+	m := Message{
+		Kind:    AuctionFinished,
+		Message: "Auction has finished",
+	}
+	if cancelReason != "" {
+		m = Message{
+			Kind:    AuctionCancelled,
+			Message: "Auction has been cancelled: " + cancelReason,
+		}
+	}
+
+	for _, client := range a.Clients {
+		a.send(client, m)
+		if _, ok := a.Clients[client.Id]; ok {
+			a.disconnect(client)
+		}
+	}
+}
+
+// settle records the auction outcome, or reads it when another instance got
+// there first, and reports whether the room can close. It keeps the room open
+// when the auction was extended by an event this instance has not seen yet,
+// and retries after SettlementRetryDelay when the outcome could not be recorded.
+func (a *AuctionRoom) settle() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), SettlementTimeout)
+	defer cancel()
+
+	result, err := a.BidsService.SettleAuction(ctx, a.Id)
+	if errors.Is(err, ErrAuctionNotEnded) {
+		var auctionEnd time.Time
+		auctionEnd, err = a.BidsService.auctionEnd(ctx, a.Id)
+		if err == nil {
+			a.AuctionEnd = auctionEnd
+			a.deadline.Reset(time.Until(auctionEnd))
+			return false
+		}
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		slog.Error("settled auction not found", "Auction ID", a.Id)
+		return true
+	}
+	if err != nil {
+		slog.Error("failed to settle auction, retrying", "Auction ID", a.Id, "error", err)
+		a.deadline.Reset(SettlementRetryDelay)
+		return false
+	}
+
+	m := settlementMessage(result, a.product.Currency)
+	for _, client := range a.Clients {
+		a.send(client, m)
+	}
+	return true
+}
+
+func settlementMessage(result pgstore.AuctionResult, currency string) Message {
+	m := Message{
+		Kind:    AuctionSettled,
+		Message: "Auction has ended without bids",
+	}
+	switch result.Status {
+	case AuctionSold, AuctionBoughtNow:
+		m.Message = "Auction has been won"
+		m.Amount = money.Amount(result.FinalPrice.Int64)
+		m.Currency = currency
+		m.UserId = uuid.UUID(result.WinnerID.Bytes)
+	case AuctionReserveNotMet:
+		reserveMet := false
+		m.Message = "Auction has ended without meeting the reserve price"
+		m.ReserveMet = &reserveMet
+	case AuctionCancelledBySeller:
+		// the cancelled event was missed, the room learns it from the result
+		m.Kind = AuctionCancelled
+		m.Message = "Auction has been cancelled: " + result.CancelReason.String
+	}
+
+	return m
+}
+
 func (a *AuctionRoom) Start() {
 	slog.Info("Starting Auction Room", "Room ID", a.Id)
+	a.deadline = time.NewTimer(time.Until(a.AuctionEnd))
+
+	var priceDrops <-chan time.Time
+	if next := nextPriceDrop(a.product, time.Now()); isDutch(a.product) && !next.IsZero() {
+		a.priceDrop = time.NewTimer(time.Until(next))
+		priceDrops = a.priceDrop.C
+	}
+
+	ticks := time.NewTicker(TickPeriod)
+
+	a.Bus.subscribe(a.Id, a.events, a.resync, a.done)
+
 	defer func() {
-		close(a.Broadcast)
-		close(a.Register)
-		close(a.Unregister)
+		a.Bus.unsubscribe(a.Id)
+		ticks.Stop()
+		a.deadline.Stop()
+		if a.priceDrop != nil {
+			a.priceDrop.Stop()
+		}
+		a.cancel()
+		close(a.done)
 	}()
 
 	for {
@@ -243,14 +909,24 @@ func (a *AuctionRoom) Start() {
 			a.unregisterClient(client)
 		case message := <-a.Broadcast:
 			a.broadcastMessage(message)
+		case event := <-a.events:
+			a.handleEvent(event)
+		case <-a.resync:
+			a.catchUp()
+		case <-priceDrops:
+			a.dropPrice()
+		case <-ticks.C:
+			a.tick()
+		case <-a.deadline.C:
+			slog.Info("AuctionRoom deadline reached", "Auction ID", a.Id)
+			if !a.settle() {
+				continue
+			}
+			a.finish("")
+			return
 		case <-a.Context.Done():
 			slog.Info("AuctionRoom stopped", "Auction ID", a.Id)
-			for _, client := range a.Clients {
-				client.Send <- Message{
-					Kind:    AuctionFinished,
-					Message: "Auction has finished",
-				}
-			}
+			a.finish(a.cancelReason)
 			return
 		}
 	}
@@ -258,5 +934,6 @@ func (a *AuctionRoom) Start() {
 
 type AuctionLobby struct {
 	sync.Mutex
-	Rooms map[uuid.UUID]*AuctionRoom
+	Rooms     map[uuid.UUID]*AuctionRoom
+	Scheduled map[uuid.UUID]*time.Timer
 }
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/google/uuid"
+	"log/slog"
+	"time"
+)
+
+// AuctionSnapshot is the state of an auction sent to a client as soon as it
+// joins the room. Sealed auctions leave every bid out of it.
+type AuctionSnapshot struct {
+	ProductId    uuid.UUID     `json:"product_id"`
+	ProductName  string        `json:"product_name"`
+	Description  string        `json:"description"`
+	AuctionType  string        `json:"auction_type"`
+	Currency     string        `json:"currency"`
+	BasePrice    money.Amount  `json:"base_price"`
+	BuyNowPrice  money.Amount  `json:"buy_now_price,omitempty"`
+	CurrentPrice money.Amount  `json:"current_price"`
+	MinNextBid   money.Amount  `json:"min_next_bid,omitempty"`
+	ReserveMet   *bool         `json:"reserve_met,omitempty"`
+	HighestBid   *SnapshotBid  `json:"highest_bid,omitempty"`
+	RecentBids   []SnapshotBid `json:"recent_bids"`
+
+	AuctionStart    time.Time `json:"auction_start"`
+	AuctionEnd      time.Time `json:"auction_end"`
+	ServerTime      time.Time `json:"server_time"`
+	TimeRemainingMs int64     `json:"time_remaining_ms"`
+	Watchers        int       `json:"watchers"`
+	Bidders         int       `json:"bidders"`
+	Spectators      int       `json:"spectators"`
+
+	// DroppedClients counts the clients the room disconnected for being too
+	// slow to follow it.
+	DroppedClients int64 `json:"dropped_clients"`
+
+	// LastSeq is the last room event reflected in the snapshot.
+	LastSeq int64 `json:"last_seq"`
+}
+
+type SnapshotBid struct {
+	UserId    uuid.UUID    `json:"user_id"`
+	Amount    money.Amount `json:"amount"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// BidsState is what bidders may know about the bids of an auction.
+type BidsState struct {
+	Highest      pgstore.Bid
+	Recent       []pgstore.Bid
+	CurrentPrice money.Amount
+	MinNextBid   money.Amount
+	ReserveMet   *bool
+}
+
+// BidsState reads the public bidding state of product, the current asking
+// price alone for dutch and sealed auctions.
+func (b *BidsService) BidsState(ctx context.Context, product pgstore.Product) (BidsState, error) {
+	if isDutch(product) {
+		return BidsState{CurrentPrice: DutchPrice(product, time.Now())}, nil
+	}
+	if isSealed(product) {
+		return BidsState{CurrentPrice: product.BasePrice}, nil
+	}
+
+	recent, err := b.queries.GetBidsByProductId(ctx, product.ID)
+	if err != nil {
+		return BidsState{}, err
+	}
+
+	state := BidsState{Recent: recent, CurrentPrice: product.BasePrice}
+	if len(recent) > 0 {
+		state.Highest = recent[0]
+		state.CurrentPrice = state.Highest.Amount
+	}
+	state.MinNextBid = b.minimumBid(product, state.Highest)
+	state.ReserveMet = reserveStatus(product, state.Highest)
+
+	return state, nil
+}
+
+// bidsState is the BidsState of the room auction. Reading the bids of an english
+// auction takes a query, so the room keeps the result until a bid event
+// changes it instead of querying for every client that joins.
+func (a *AuctionRoom) bidsState() (BidsState, error) {
+	if isDutch(a.product) || isSealed(a.product) {
+		return a.BidsService.BidsState(a.Context, a.product)
+	}
+	if a.bids != nil {
+		return *a.bids, nil
+	}
+
+	state, err := a.BidsService.BidsState(a.Context, a.product)
+	if err != nil {
+		return BidsState{}, err
+	}
+	a.bids = &state
+	return state, nil
+}
+
+// snapshot builds the message a client gets right after joining the room.
+func (a *AuctionRoom) snapshot() Message {
+	now := time.Now()
+	s := AuctionSnapshot{
+		ProductId:       a.product.ID,
+		ProductName:     a.product.ProductName,
+		Description:     a.product.Description,
+		AuctionType:     a.product.AuctionType,
+		Currency:        a.product.Currency,
+		BasePrice:       a.product.BasePrice,
+		BuyNowPrice:     a.product.BuyNowPrice,
+		CurrentPrice:    a.product.BasePrice,
+		RecentBids:      []SnapshotBid{},
+		AuctionStart:    a.product.AuctionStart,
+		AuctionEnd:      a.AuctionEnd,
+		ServerTime:      now,
+		TimeRemainingMs: max(a.AuctionEnd.Sub(now), 0).Milliseconds(),
+		Watchers:        len(a.Clients),
+		Bidders:         a.bidders,
+		Spectators:      a.spectators,
+		DroppedClients:  a.DroppedClients(),
+		LastSeq:         a.lastSeq,
+	}
+
+	state, err := a.bidsState()
+	if err != nil {
+		slog.Error("failed to read auction state", "Auction ID", a.Id, "error", err)
+	} else {
+		s.CurrentPrice = state.CurrentPrice
+		s.MinNextBid = state.MinNextBid
+		s.ReserveMet = state.ReserveMet
+		for _, bid := range state.Recent {
+			s.RecentBids = append(s.RecentBids, SnapshotBid{
+				UserId:    bid.BidderID,
+				Amount:    bid.Amount,
+				CreatedAt: bid.CreatedAt,
+			})
+		}
+		if len(s.RecentBids) > 0 {
+			s.HighestBid = &s.RecentBids[0]
+		}
+	}
+
+	return Message{
+		Kind:     Snapshot,
+		Message:  "Auction snapshot",
+		Currency: a.product.Currency,
+		Snapshot: &s,
+	}
+}
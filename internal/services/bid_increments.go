@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"strings"
+)
+
+// BidIncrement is the minimum raise over prices below UpTo. A zero UpTo
+// matches every price, so it belongs in the last tier.
+type BidIncrement struct {
+	UpTo money.Amount
+	Step money.Amount
+}
+
+type BidIncrements []BidIncrement
+
+// DefaultBidIncrements applies to every product without its own bid increment.
+var DefaultBidIncrements = BidIncrements{
+	{UpTo: 100 * money.Unit, Step: 1 * money.Unit},
+	{UpTo: 1000 * money.Unit, Step: 5 * money.Unit},
+	{UpTo: 0, Step: 10 * money.Unit},
+}
+
+var ErrInvalidBidIncrements = errors.New("invalid bid increments")
+
+// ParseBidIncrements reads tiers written as "upTo:step" pairs separated by
+// commas, from the lowest to the highest, such as "100:1,1000:5,*:10". The last
+// tier has "*" as upTo and covers every higher price.
+func ParseBidIncrements(s string) (BidIncrements, error) {
+	var increments BidIncrements
+	tiers := strings.Split(s, ",")
+	for i, raw := range tiers {
+		rawUpTo, rawStep, ok := strings.Cut(strings.TrimSpace(raw), ":")
+		if !ok {
+			return nil, ErrInvalidBidIncrements
+		}
+
+		step, err := money.Parse(rawStep)
+		if err != nil || step <= 0 {
+			return nil, ErrInvalidBidIncrements
+		}
+
+		last := i == len(tiers)-1
+		if rawUpTo == "*" {
+			if !last {
+				return nil, ErrInvalidBidIncrements
+			}
+			increments = append(increments, BidIncrement{UpTo: 0, Step: step})
+			continue
+		}
+		if last {
+			return nil, ErrInvalidBidIncrements
+		}
+
+		upTo, err := money.Parse(rawUpTo)
+		if err != nil || upTo <= 0 || (i > 0 && upTo <= increments[i-1].UpTo) {
+			return nil, ErrInvalidBidIncrements
+		}
+		increments = append(increments, BidIncrement{UpTo: upTo, Step: step})
+	}
+
+	return increments, nil
+}
+
+// WithBidIncrements replaces DefaultBidIncrements.
+func WithBidIncrements(increments BidIncrements) BidsOption {
+	return func(b *BidsService) {
+		b.increments = increments
+	}
+}
+
+func (bi BidIncrements) Step(price money.Amount) money.Amount {
+	for _, tier := range bi {
+		if tier.UpTo == 0 || price < tier.UpTo {
+			return tier.Step
+		}
+	}
+	return 0
+}
+
+func (b *BidsService) increment(product pgstore.Product, price money.Amount) money.Amount {
+	if product.BidIncrement > 0 {
+		return product.BidIncrement
+	}
+	return b.increments.Step(price)
+}
+
+// minimumBid is the lowest amount accepted over the current price of product.
+func (b *BidsService) minimumBid(product pgstore.Product, highest pgstore.Bid) money.Amount {
+	price := max(product.BasePrice, highest.Amount)
+	return price + b.increment(product, price)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"slices"
+	"testing"
+)
+
+func TestParseBidIncrements(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    BidIncrements
+		wantErr bool
+	}{
+		{in: "100:1,1000:5,*:10", want: DefaultBidIncrements},
+		{in: "*:0.50", want: BidIncrements{{UpTo: 0, Step: 50}}},
+		{in: " 10.50:0.25 , *:1", want: BidIncrements{{UpTo: 1050, Step: 25}, {UpTo: 0, Step: 100}}},
+		{in: "", wantErr: true},
+		{in: "100:1", wantErr: true},
+		{in: "*:1,100:5", wantErr: true},
+		{in: "1000:5,100:1,*:10", wantErr: true},
+		{in: "100:0,*:10", wantErr: true},
+		{in: "100:1.005,*:10", wantErr: true},
+		{in: "100,*:10", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseBidIncrements(tt.in)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseBidIncrements(%q) = %v, want error", tt.in, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBidIncrements(%q) error: %v", tt.in, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseBidIncrements(%q) = %v, want %v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBidIncrementsStep(t *testing.T) {
+	tests := []struct {
+		price money.Amount
+		want  money.Amount
+	}{
+		{price: 0, want: 1 * money.Unit},
+		{price: 9999, want: 1 * money.Unit},
+		{price: 100 * money.Unit, want: 5 * money.Unit},
+		{price: 1000 * money.Unit, want: 10 * money.Unit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.price.String(), func(t *testing.T) {
+			if got := DefaultBidIncrements.Step(tt.price); got != tt.want {
+				t.Errorf("Step(%s) = %s, want %s", tt.price, got, tt.want)
+			}
+		})
+	}
+}
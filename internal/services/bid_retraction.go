@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"time"
+)
+
+var (
+	ErrNoBidToRetract       = errors.New("there is no bid to retract")
+	ErrRetractionNotAllowed = errors.New("the bid can no longer be retracted")
+)
+
+// RetractionPolicy decides when bidders may withdraw their latest bid.
+type RetractionPolicy struct {
+	// Window is how long after being placed a bid can still be retracted.
+	Window time.Duration
+	// ClosingPeriod is the final stretch of the auction in which no bid can be
+	// retracted anymore.
+	ClosingPeriod time.Duration
+}
+
+var DefaultRetractionPolicy = RetractionPolicy{
+	Window:        5 * time.Minute,
+	ClosingPeriod: time.Hour,
+}
+
+// WithRetractionPolicy replaces DefaultRetractionPolicy.
+func WithRetractionPolicy(policy RetractionPolicy) BidsOption {
+	return func(b *BidsService) {
+		b.retraction = policy
+	}
+}
+
+// allows reports whether bid may still be retracted from an auction ending at
+// auctionEnd.
+func (p RetractionPolicy) allows(bid pgstore.Bid, auctionEnd time.Time) bool {
+	now := time.Now()
+	return now.Sub(bid.CreatedAt) <= p.Window && auctionEnd.Sub(now) > p.ClosingPeriod
+}
+
+// RetractedBid is the outcome of a retraction. Highest is the leading bid once
+// the retracted one is left out, a zero Bid when none remains.
+type RetractedBid struct {
+	Bid        pgstore.Bid
+	Highest    pgstore.Bid
+	MinNextBid money.Amount
+	ReserveMet *bool
+	Sealed     bool
+
+	// Events are the room events published for the retraction.
+	Events []Message
+}
+
+// RetractBid withdraws the latest bid of bidder along with its maximum bid. The
+// bid is kept flagged as retracted so the auction history stays auditable.
+func (b *BidsService) RetractBid(ctx context.Context, productId, bidder uuid.UUID) (RetractedBid, error) {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return RetractedBid{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := b.queries.WithTx(tx)
+
+	product, err := queries.GetProductByIdForUpdate(ctx, productId)
+	if err != nil {
+		return RetractedBid{}, err
+	}
+	if err := checkAuctionOpen(product); err != nil {
+		return RetractedBid{}, err
+	}
+	if isDutch(product) {
+		return RetractedBid{}, ErrUnsupportedAuctionType
+	}
+
+	bid, err := queries.GetLatestBidByBidder(ctx, pgstore.GetLatestBidByBidderParams{
+		ProductID: productId,
+		BidderID:  bidder,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return RetractedBid{}, ErrNoBidToRetract
+		}
+		return RetractedBid{}, err
+	}
+	if !b.retraction.allows(bid, product.AuctionEnd) {
+		return RetractedBid{}, ErrRetractionNotAllowed
+	}
+
+	bid, err = queries.RetractBid(ctx, bid.ID)
+	if err != nil {
+		return RetractedBid{}, err
+	}
+
+	err = queries.DeleteMaxBid(ctx, pgstore.DeleteMaxBidParams{
+		ProductID: productId,
+		BidderID:  bidder,
+	})
+	if err != nil {
+		return RetractedBid{}, err
+	}
+
+	retracted := RetractedBid{Bid: bid, Sealed: isSealed(product)}
+	if !retracted.Sealed {
+		bids, err := queries.GetHighestBidByProductId(ctx, productId)
+		if err != nil {
+			return RetractedBid{}, err
+		}
+		if len(bids) > 0 {
+			retracted.Highest = bids[0]
+		}
+
+		retracted.Highest, err = b.resolveProxyBids(ctx, queries, product, retracted.Highest)
+		if err != nil {
+			return RetractedBid{}, err
+		}
+		retracted.MinNextBid = b.minimumBid(product, retracted.Highest)
+		retracted.ReserveMet = reserveStatus(product, retracted.Highest)
+
+		retracted.Events, err = b.publish(ctx, queries, productId, Message{
+			Kind:       PriceCorrected,
+			Message:    "A bid has been retracted, the price was corrected",
+			Amount:     retracted.Highest.Amount,
+			UserId:     retracted.Highest.BidderID,
+			ReserveMet: retracted.ReserveMet,
+			MinNextBid: retracted.MinNextBid,
+			Currency:   product.Currency,
+		})
+		if err != nil {
+			return RetractedBid{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return RetractedBid{}, err
+	}
+
+	return retracted, nil
+}
@@ -3,39 +3,184 @@ package services
 import (
 	"context"
 	"errors"
-	"fmt"
+	"github.com/JoaoRafa19/gobid/internal/money"
 	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"time"
 )
 
 type BidsService struct {
-	pool    *pgxpool.Pool
-	queries *pgstore.Queries
+	pool       *pgxpool.Pool
+	queries    *pgstore.Queries
+	bus        *AuctionBus
+	increments BidIncrements
+	retraction RetractionPolicy
 }
 
-var ErrBidIsTooLow = errors.New("the bid value is too low")
+// BidsOption configures a BidsService.
+type BidsOption func(*BidsService)
 
-func NewBidsService(pool *pgxpool.Pool) *BidsService {
-	return &BidsService{
-		pool:    pool,
-		queries: pgstore.New(pool),
+// WithAuctionBus publishes the events of every change to an auction through
+// bus, in the transaction of the change.
+func WithAuctionBus(bus *AuctionBus) BidsOption {
+	return func(b *BidsService) {
+		b.bus = bus
 	}
 }
 
-func (b *BidsService) PlaceBid(ctx context.Context, productId, bidder uuid.UUID, amount float64) (pgstore.Bid, error) {
-	product, err := b.queries.GetProductById(ctx, productId)
+var (
+	ErrBidIsTooLow       = errors.New("the bid value is too low")
+	ErrBidBelowIncrement = errors.New("the bid does not meet the minimum increment")
+	ErrInvalidMaxBid     = errors.New("the maximum bid can not be lower than the bid")
+	ErrAuctionClosed     = errors.New("the auction is closed")
+	ErrAuctionNotStarted = errors.New("the auction has not started yet")
+	ErrAuctionNotEnded   = errors.New("the auction has not ended yet")
+	ErrBuyNowUnavailable = errors.New("buy it now is not available for this auction")
+	ErrCurrencyMismatch  = errors.New("bids must be placed in the auction currency")
+
+	ErrUnsupportedAuctionType = errors.New("this action is not supported by the auction type")
+)
+
+// PlacedBid is the outcome of an accepted bid. Highest is the leading bid once
+// proxy bids were resolved, and AuctionEnd holds the auction deadline after the
+// bid, which moves forward when the bid lands in the soft close window.
+// ReserveMet is nil when the product has no reserve price. Sealed bids only
+// fill Bid and AuctionEnd, as nothing about the other bids may be revealed.
+type PlacedBid struct {
+	Bid        pgstore.Bid
+	Highest    pgstore.Bid
+	MinNextBid money.Amount
+	AuctionEnd time.Time
+	Extended   bool
+	ReserveMet *bool
+	Sealed     bool
+
+	// Events are the room events published for the bid.
+	Events []Message
+}
+
+// Sale is the outcome of an auction closed by a purchase.
+type Sale struct {
+	Result pgstore.AuctionResult
+	Events []Message
+}
+
+func NewBidsService(pool *pgxpool.Pool, opts ...BidsOption) *BidsService {
+	b := &BidsService{
+		pool:       pool,
+		queries:    pgstore.New(pool),
+		increments: DefaultBidIncrements,
+		retraction: DefaultRetractionPolicy,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// publish appends events to the auction of productId in the transaction of
+// queries, see AuctionBus.append. Without a bus the events are returned as they
+// are, unnumbered.
+func (b *BidsService) publish(ctx context.Context, queries *pgstore.Queries, productId uuid.UUID, events ...Message) ([]Message, error) {
+	if b.bus == nil {
+		return events, nil
+	}
+
+	published := make([]Message, 0, len(events))
+	for _, m := range events {
+		m, err := b.bus.append(ctx, queries, productId, m)
+		if err != nil {
+			return nil, err
+		}
+		published = append(published, m)
+	}
+	return published, nil
+}
+
+// PlaceBid runs with the product row locked, so concurrent bids on the same
+// auction are applied one after the other in the order they got the lock and
+// a bid that is no longer above the leading one fails with ErrBidIsTooLow.
+// An empty currency stands for the auction currency.
+func (b *BidsService) PlaceBid(ctx context.Context, productId, bidder uuid.UUID, amount, maxAmount money.Amount, currency string) (PlacedBid, error) {
+	if maxAmount != 0 && maxAmount < amount {
+		return PlacedBid{}, ErrInvalidMaxBid
+	}
+
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return PlacedBid{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := b.queries.WithTx(tx)
+
+	product, err := queries.GetProductByIdForUpdate(ctx, productId)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
-			return pgstore.Bid{}, err
+			return PlacedBid{}, err
 		}
 	}
 
-	bids, err := b.queries.GetHighestBidByProductId(ctx, productId)
+	if err := checkAuctionOpen(product); err != nil {
+		return PlacedBid{}, err
+	}
+	if currency != "" && currency != product.Currency {
+		return PlacedBid{}, ErrCurrencyMismatch
+	}
+	if isDutch(product) {
+		return PlacedBid{}, ErrUnsupportedAuctionType
+	}
+
+	var placed PlacedBid
+	if isSealed(product) {
+		placed, err = b.placeSealedBid(ctx, queries, product, bidder, amount, maxAmount)
+	} else {
+		placed, err = b.placeBid(ctx, queries, product, bidder, amount, maxAmount)
+	}
+	if err != nil {
+		return PlacedBid{}, err
+	}
+
+	// sealed bids stay hidden from the room
+	if !placed.Sealed {
+		events := []Message{{
+			Kind:       NewBidPlaced,
+			Message:    "New bid has been placed!",
+			Amount:     placed.Highest.Amount,
+			UserId:     placed.Highest.BidderID,
+			ReserveMet: placed.ReserveMet,
+			MinNextBid: placed.MinNextBid,
+			Currency:   product.Currency,
+		}}
+		if placed.Extended {
+			events = append(events, Message{
+				Kind:       AuctionExtended,
+				Message:    "Auction has been extended",
+				AuctionEnd: &placed.AuctionEnd,
+			})
+		}
+
+		placed.Events, err = b.publish(ctx, queries, productId, events...)
+		if err != nil {
+			return PlacedBid{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return PlacedBid{}, err
+	}
+
+	return placed, nil
+}
+
+func (b *BidsService) placeBid(ctx context.Context, queries *pgstore.Queries, product pgstore.Product, bidder uuid.UUID, amount, maxAmount money.Amount) (PlacedBid, error) {
+	bids, err := queries.GetHighestBidByProductId(ctx, product.ID)
 	if err != nil {
 		if !errors.Is(err, pgx.ErrNoRows) {
-			return pgstore.Bid{}, nil
+			return PlacedBid{}, err
 		}
 	}
 	var highestBid pgstore.Bid
@@ -46,20 +191,282 @@ func (b *BidsService) PlaceBid(ctx context.Context, productId, bidder uuid.UUID,
 	}
 
 	if product.BasePrice >= amount || highestBid.Amount >= amount {
-		fmt.Println("HIGHEST BID", highestBid)
-		fmt.Println("BID ", amount)
-		return pgstore.Bid{}, ErrBidIsTooLow
+		return PlacedBid{}, ErrBidIsTooLow
 	}
 
-	highestBid, err = b.queries.CreateBid(ctx, pgstore.CreateBidParams{
-		ProductID: productId,
+	if amount < b.minimumBid(product, highestBid) {
+		return PlacedBid{}, ErrBidBelowIncrement
+	}
+
+	bid, err := queries.CreateBid(ctx, pgstore.CreateBidParams{
+		ProductID: product.ID,
 		BidderID:  bidder,
 		Amount:    amount,
+		Currency:  product.Currency,
 	})
 
+	if err != nil {
+		return PlacedBid{}, err
+	}
+
+	if maxAmount != 0 {
+		err := queries.UpsertMaxBid(ctx, pgstore.UpsertMaxBidParams{
+			ProductID: product.ID,
+			BidderID:  bidder,
+			MaxAmount: maxAmount,
+		})
+		if err != nil {
+			return PlacedBid{}, err
+		}
+	}
+
+	highestBid, err = b.resolveProxyBids(ctx, queries, product, bid)
+	if err != nil {
+		return PlacedBid{}, err
+	}
+
+	placed := PlacedBid{
+		Bid:        bid,
+		Highest:    highestBid,
+		MinNextBid: b.minimumBid(product, highestBid),
+		AuctionEnd: product.AuctionEnd,
+		ReserveMet: reserveStatus(product, highestBid),
+	}
+
+	softClose := time.Duration(product.SoftCloseMinutes) * time.Minute
+	if softClose > 0 && time.Until(product.AuctionEnd) <= softClose {
+		auctionEnd, err := queries.ExtendAuctionEnd(ctx, pgstore.ExtendAuctionEndParams{
+			ID:         product.ID,
+			AuctionEnd: product.AuctionEnd.Add(time.Duration(product.ExtensionMinutes) * time.Minute),
+		})
+		if err != nil {
+			return PlacedBid{}, err
+		}
+
+		placed.AuctionEnd = auctionEnd
+		placed.Extended = true
+	}
+
+	return placed, nil
+}
+
+// checkAuctionOpen reports why p does not take bids, if it does not.
+func checkAuctionOpen(p pgstore.Product) error {
+	if p.Sold || !time.Now().Before(p.AuctionEnd) {
+		return ErrAuctionClosed
+	}
+	if time.Now().Before(p.AuctionStart) {
+		return ErrAuctionNotStarted
+	}
+	return nil
+}
+
+// reserveStatus reports whether highest meets the reserve price of p, nil when
+// p has no reserve price.
+func reserveStatus(p pgstore.Product, highest pgstore.Bid) *bool {
+	if p.ReservePrice == 0 {
+		return nil
+	}
+	reserveMet := highest.Amount >= p.ReservePrice
+	return &reserveMet
+}
+
+// resolveProxyBids bids on behalf of the strongest maximum bid, raising the
+// price only as far as needed to beat the runner-up.
+func (b *BidsService) resolveProxyBids(ctx context.Context, queries *pgstore.Queries, product pgstore.Product, highest pgstore.Bid) (pgstore.Bid, error) {
+	maxBids, err := queries.GetTopMaxBidsByProductId(ctx, product.ID)
 	if err != nil {
 		return pgstore.Bid{}, err
 	}
+	if len(maxBids) == 0 {
+		return highest, nil
+	}
+
+	leader := maxBids[0]
+
+	var challenger money.Amount
+	if leader.BidderID != highest.BidderID {
+		challenger = highest.Amount
+	}
+	if len(maxBids) > 1 && maxBids[1].MaxAmount > challenger {
+		challenger = maxBids[1].MaxAmount
+	}
 
-	return highestBid, nil
+	price := min(leader.MaxAmount, challenger+b.increment(product, challenger))
+	if price <= highest.Amount {
+		return highest, nil
+	}
+
+	return queries.CreateBid(ctx, pgstore.CreateBidParams{
+		ProductID: product.ID,
+		BidderID:  leader.BidderID,
+		Amount:    price,
+		Currency:  product.Currency,
+	})
+}
+
+const (
+	AuctionSold          = "sold"
+	AuctionUnsold        = "unsold"
+	AuctionReserveNotMet = "reserve_not_met"
+	AuctionBoughtNow     = "bought_now"
+
+	AuctionCancelledBySeller = "cancelled"
+)
+
+// BuyNow sells the product to buyer at its buy it now price, which is only
+// offered while no bid has reached that price.
+func (b *BidsService) BuyNow(ctx context.Context, productId, buyer uuid.UUID) (Sale, error) {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return Sale{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := b.queries.WithTx(tx)
+
+	product, err := queries.GetProductByIdForUpdate(ctx, productId)
+	if err != nil {
+		return Sale{}, err
+	}
+	if err := checkAuctionOpen(product); err != nil {
+		return Sale{}, err
+	}
+	if product.BuyNowPrice == 0 {
+		return Sale{}, ErrBuyNowUnavailable
+	}
+
+	bids, err := queries.GetHighestBidByProductId(ctx, productId)
+	if err != nil {
+		return Sale{}, err
+	}
+	if len(bids) > 0 && bids[0].Amount >= product.BuyNowPrice {
+		return Sale{}, ErrBuyNowUnavailable
+	}
+
+	result, err := sell(ctx, queries, product, buyer, product.BuyNowPrice, AuctionBoughtNow)
+	if err != nil {
+		return Sale{}, err
+	}
+
+	events, err := b.publish(ctx, queries, productId, Message{
+		Kind:     BoughtNow,
+		Message:  "Item has been bought now",
+		Amount:   money.Amount(result.FinalPrice.Int64),
+		UserId:   buyer,
+		Currency: product.Currency,
+	})
+	if err != nil {
+		return Sale{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Sale{}, err
+	}
+
+	return Sale{Result: result, Events: events}, nil
+}
+
+// sell places the winning bid for buyer at price and closes the auction with it.
+func sell(ctx context.Context, queries *pgstore.Queries, product pgstore.Product, buyer uuid.UUID, price money.Amount, status string) (pgstore.AuctionResult, error) {
+	bid, err := queries.CreateBid(ctx, pgstore.CreateBidParams{
+		ProductID: product.ID,
+		BidderID:  buyer,
+		Amount:    price,
+		Currency:  product.Currency,
+	})
+	if err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	if err := queries.MarkProductAsSold(ctx, product.ID); err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	return queries.CreateAuctionResult(ctx, pgstore.CreateAuctionResultParams{
+		ProductID:    product.ID,
+		Status:       status,
+		WinningBidID: pgtype.UUID{Bytes: bid.ID, Valid: true},
+		WinnerID:     pgtype.UUID{Bytes: buyer, Valid: true},
+		FinalPrice:   pgtype.Int8{Int64: int64(bid.Amount), Valid: true},
+	})
+}
+
+// SettleAuction records the outcome of a closed auction. It is safe to call more
+// than once, from any instance: an auction that was already settled returns the
+// stored result, and only the first call past the auction end writes one.
+func (b *BidsService) SettleAuction(ctx context.Context, productId uuid.UUID) (pgstore.AuctionResult, error) {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := b.queries.WithTx(tx)
+
+	product, err := queries.GetProductByIdForUpdate(ctx, productId)
+	if err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	result, err := queries.GetAuctionResultByProductId(ctx, productId)
+	if err == nil {
+		return result, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return pgstore.AuctionResult{}, err
+	}
+	if time.Now().Before(product.AuctionEnd) {
+		return pgstore.AuctionResult{}, ErrAuctionNotEnded
+	}
+
+	var bids []pgstore.Bid
+	if isSealed(product) {
+		bids, err = queries.GetTopSealedBidsByProductId(ctx, productId)
+	} else {
+		bids, err = queries.GetHighestBidByProductId(ctx, productId)
+	}
+	if err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	params := pgstore.CreateAuctionResultParams{
+		ProductID: productId,
+		Status:    AuctionUnsold,
+	}
+
+	switch {
+	case len(bids) == 0:
+	case bids[0].Amount < product.ReservePrice:
+		params.Status = AuctionReserveNotMet
+	default:
+		winningBid := bids[0]
+		params.Status = AuctionSold
+		params.WinningBidID = pgtype.UUID{Bytes: winningBid.ID, Valid: true}
+		params.WinnerID = pgtype.UUID{Bytes: winningBid.BidderID, Valid: true}
+		params.FinalPrice = pgtype.Int8{Int64: int64(winningPrice(product, bids)), Valid: true}
+
+		if err := queries.MarkProductAsSold(ctx, productId); err != nil {
+			return pgstore.AuctionResult{}, err
+		}
+	}
+
+	result, err = queries.CreateAuctionResult(ctx, params)
+	if err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	return result, nil
+}
+
+func (b *BidsService) auctionEnd(ctx context.Context, productId uuid.UUID) (time.Time, error) {
+	product, err := b.queries.GetProductById(ctx, productId)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return product.AuctionEnd, nil
 }
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/JoaoRafa19/gobid/internal/usecase/product"
+	"github.com/google/uuid"
+	"time"
+)
+
+func isDutch(p pgstore.Product) bool {
+	return p.AuctionType == product.AuctionTypeDutch
+}
+
+func priceDropInterval(p pgstore.Product) time.Duration {
+	return time.Duration(p.PriceDropSeconds) * time.Second
+}
+
+// DutchPrice is the asking price of a dutch auction at the given time.
+func DutchPrice(p pgstore.Product, at time.Time) money.Amount {
+	interval := priceDropInterval(p)
+	if interval <= 0 || at.Before(p.AuctionStart) {
+		return p.BasePrice
+	}
+
+	drops := money.Amount(at.Sub(p.AuctionStart) / interval)
+	return max(p.BasePrice-drops*p.PriceDrop, p.PriceFloor)
+}
+
+// nextPriceDrop is when the asking price changes after at, or the zero time
+// once the price has reached its floor.
+func nextPriceDrop(p pgstore.Product, at time.Time) time.Time {
+	interval := priceDropInterval(p)
+	if interval <= 0 || DutchPrice(p, at) <= p.PriceFloor {
+		return time.Time{}
+	}
+
+	elapsed := max(at.Sub(p.AuctionStart), 0)
+	return p.AuctionStart.Add((elapsed/interval + 1) * interval)
+}
+
+// AcceptDutchPrice sells the product to buyer at the current asking price.
+func (b *BidsService) AcceptDutchPrice(ctx context.Context, productId, buyer uuid.UUID) (Sale, error) {
+	tx, err := b.pool.Begin(ctx)
+	if err != nil {
+		return Sale{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := b.queries.WithTx(tx)
+
+	p, err := queries.GetProductByIdForUpdate(ctx, productId)
+	if err != nil {
+		return Sale{}, err
+	}
+	if !isDutch(p) {
+		return Sale{}, ErrUnsupportedAuctionType
+	}
+	if err := checkAuctionOpen(p); err != nil {
+		return Sale{}, err
+	}
+
+	result, err := sell(ctx, queries, p, buyer, DutchPrice(p, time.Now()), AuctionSold)
+	if err != nil {
+		return Sale{}, err
+	}
+
+	events, err := b.publish(ctx, queries, productId, settlementMessage(result, p.Currency))
+	if err != nil {
+		return Sale{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Sale{}, err
+	}
+
+	return Sale{Result: result, Events: events}, nil
+}
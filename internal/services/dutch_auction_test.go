@@ -0,0 +1,75 @@
+package services
+
+import (
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/JoaoRafa19/gobid/internal/usecase/product"
+	"testing"
+	"time"
+)
+
+var dutchStart = time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+func dutchProduct(dropSeconds int32) pgstore.Product {
+	return pgstore.Product{
+		AuctionType:      product.AuctionTypeDutch,
+		AuctionStart:     dutchStart,
+		BasePrice:        1000,
+		PriceFloor:       400,
+		PriceDrop:        100,
+		PriceDropSeconds: dropSeconds,
+	}
+}
+
+func TestDutchPrice(t *testing.T) {
+	tests := []struct {
+		name        string
+		dropSeconds int32
+		at          time.Duration
+		want        money.Amount
+	}{
+		{name: "before the start", dropSeconds: 60, at: -time.Minute, want: 1000},
+		{name: "at the start", dropSeconds: 60, at: 0, want: 1000},
+		{name: "just before the first drop", dropSeconds: 60, at: 59 * time.Second, want: 1000},
+		{name: "at the first drop", dropSeconds: 60, at: time.Minute, want: 900},
+		{name: "between drops", dropSeconds: 60, at: 150 * time.Second, want: 800},
+		{name: "at the floor", dropSeconds: 60, at: 6 * time.Minute, want: 400},
+		{name: "long after the floor", dropSeconds: 60, at: time.Hour, want: 400},
+		{name: "without drop interval", dropSeconds: 0, at: time.Hour, want: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := dutchProduct(tt.dropSeconds)
+			if got := DutchPrice(p, dutchStart.Add(tt.at)); got != tt.want {
+				t.Errorf("DutchPrice() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextPriceDrop(t *testing.T) {
+	tests := []struct {
+		name        string
+		dropSeconds int32
+		at          time.Duration
+		want        time.Time
+	}{
+		{name: "before the start", dropSeconds: 60, at: -time.Minute, want: dutchStart.Add(time.Minute)},
+		{name: "at the start", dropSeconds: 60, at: 0, want: dutchStart.Add(time.Minute)},
+		{name: "between drops", dropSeconds: 60, at: 90 * time.Second, want: dutchStart.Add(2 * time.Minute)},
+		{name: "at a drop", dropSeconds: 60, at: 2 * time.Minute, want: dutchStart.Add(3 * time.Minute)},
+		{name: "last drop before the floor", dropSeconds: 60, at: 5 * time.Minute, want: dutchStart.Add(6 * time.Minute)},
+		{name: "at the floor", dropSeconds: 60, at: 6 * time.Minute, want: time.Time{}},
+		{name: "without drop interval", dropSeconds: 0, at: 0, want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := dutchProduct(tt.dropSeconds)
+			if got := nextPriceDrop(p, dutchStart.Add(tt.at)); !got.Equal(tt.want) {
+				t.Errorf("nextPriceDrop() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
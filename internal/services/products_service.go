@@ -1,11 +1,15 @@
 package services
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"github.com/JoaoRafa19/gobid/internal/money"
 	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/JoaoRafa19/gobid/internal/usecase/product"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"time"
 )
@@ -15,7 +19,10 @@ type ProductsService struct {
 	queries *pgstore.Queries
 }
 
-var ErrProductNotFound = errors.New("product not found")
+var (
+	ErrProductNotFound  = errors.New("product not found")
+	ErrNotProductSeller = errors.New("only the seller can manage this auction")
+)
 
 func NewProductsService(pool *pgxpool.Pool) *ProductsService {
 	return &ProductsService{
@@ -27,22 +34,30 @@ func NewProductsService(pool *pgxpool.Pool) *ProductsService {
 func (p *ProductsService) CreateProduct(
 	ctx context.Context,
 	sellerId uuid.UUID,
-	productName,
-	description string,
-	basePrice float64,
-	auctionEnd time.Time,
-) (uuid.UUID, error) {
-	id, err := p.queries.CreateProduct(ctx, pgstore.CreateProductParams{
-		SellerID:    sellerId,
-		ProductName: productName,
-		Description: description,
-		BasePrice:   basePrice,
-		AuctionEnd:  auctionEnd,
+	req product.CreateProductRequest,
+) (pgstore.Product, error) {
+	created, err := p.queries.CreateProduct(ctx, pgstore.CreateProductParams{
+		SellerID:         sellerId,
+		ProductName:      req.ProductName,
+		Description:      req.Description,
+		BasePrice:        req.BasePrice,
+		AuctionEnd:       req.AuctionEnd,
+		SoftCloseMinutes: req.SoftCloseMinutes,
+		ExtensionMinutes: req.ExtensionMinutes,
+		ReservePrice:     req.ReservePrice,
+		BuyNowPrice:      req.BuyNowPrice,
+		BidIncrement:     req.BidIncrement,
+		AuctionType:      cmp.Or(req.AuctionType, product.AuctionTypeEnglish),
+		PriceFloor:       req.PriceFloor,
+		PriceDrop:        req.PriceDrop,
+		PriceDropSeconds: req.PriceDropSeconds,
+		AuctionStart:     req.StartsAt(),
+		Currency:         cmp.Or(req.Currency, product.CurrencyBRL),
 	})
 	if err != nil {
-		return uuid.Nil, err
+		return pgstore.Product{}, err
 	}
-	return id, nil
+	return created, nil
 }
 
 func (p *ProductsService) GetProductById(ctx context.Context, id uuid.UUID) (pgstore.Product, error) {
@@ -56,3 +71,135 @@ func (p *ProductsService) GetProductById(ctx context.Context, id uuid.UUID) (pgs
 
 	return product, nil
 }
+
+// CurrentPrice is the public price of an auction: the asking price of a dutch
+// auction, the leading bid of an english one, and the base price while there
+// are no bids or they are sealed.
+func (p *ProductsService) CurrentPrice(ctx context.Context, product pgstore.Product) (money.Amount, error) {
+	if isDutch(product) {
+		return DutchPrice(product, time.Now()), nil
+	}
+	if isSealed(product) {
+		return product.BasePrice, nil
+	}
+
+	bids, err := p.queries.GetHighestBidByProductId(ctx, product.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(bids) == 0 {
+		return product.BasePrice, nil
+	}
+	return bids[0].Amount, nil
+}
+
+// ReferencePrice is an amount converted with the stored FX rates. It is only
+// informative, bids are always placed in the auction currency.
+type ReferencePrice struct {
+	Currency string       `json:"currency"`
+	Amount   money.Amount `json:"amount"`
+}
+
+func (p *ProductsService) ReferencePrices(ctx context.Context, amount money.Amount, currency string) ([]ReferencePrice, error) {
+	rows, err := p.queries.ListReferencePrices(ctx, pgstore.ListReferencePricesParams{
+		Amount:   int64(amount),
+		Currency: currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make([]ReferencePrice, 0, len(rows))
+	for _, row := range rows {
+		prices = append(prices, ReferencePrice{
+			Currency: row.QuoteCurrency,
+			Amount:   money.Amount(row.Amount),
+		})
+	}
+
+	return prices, nil
+}
+
+// IsAuctionOpen reports whether the auction of a product still takes bids,
+// whichever instance it was created on.
+func (p *ProductsService) IsAuctionOpen(ctx context.Context, id uuid.UUID) (bool, error) {
+	return p.queries.IsAuctionOpen(ctx, id)
+}
+
+func (p *ProductsService) ListOpenAuctions(ctx context.Context) ([]pgstore.Product, error) {
+	products, err := p.queries.ListOpenAuctions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// ListUnsettledAuctions lists the auctions that ended without a recorded
+// outcome, such as those that ended while no instance was running.
+func (p *ProductsService) ListUnsettledAuctions(ctx context.Context) ([]pgstore.Product, error) {
+	products, err := p.queries.ListUnsettledAuctions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// CancelAuction withdraws a running auction on behalf of its seller and voids
+// every bid placed on it. The auction ends right away, so bids reaching a room
+// before it learns about the cancellation are refused.
+func (p *ProductsService) CancelAuction(ctx context.Context, productId, sellerId uuid.UUID, reason string) (pgstore.AuctionResult, error) {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := p.queries.WithTx(tx)
+
+	product, err := queries.GetProductByIdForUpdate(ctx, productId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgstore.AuctionResult{}, ErrProductNotFound
+		}
+		return pgstore.AuctionResult{}, err
+	}
+	if product.SellerID != sellerId {
+		return pgstore.AuctionResult{}, ErrNotProductSeller
+	}
+	if product.Sold || !product.AuctionEnd.After(time.Now()) {
+		return pgstore.AuctionResult{}, ErrAuctionClosed
+	}
+
+	_, err = queries.GetAuctionResultByProductId(ctx, productId)
+	if err == nil {
+		return pgstore.AuctionResult{}, ErrAuctionClosed
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return pgstore.AuctionResult{}, err
+	}
+
+	if err := queries.CloseAuction(ctx, productId); err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	if err := queries.VoidBidsByProductId(ctx, productId); err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	result, err := queries.CreateAuctionResult(ctx, pgstore.CreateAuctionResultParams{
+		ProductID:    productId,
+		Status:       AuctionCancelledBySeller,
+		CancelReason: pgtype.Text{String: reason, Valid: true},
+	})
+	if err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return pgstore.AuctionResult{}, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+)
+
+// Subprotocols a websocket client can ask for. Clients that ask for none get
+// the v1 protocol.
+const (
+	SubprotocolV1 = "gobid.v1"
+	SubprotocolV2 = "gobid.v2"
+)
+
+// ProtocolV2Schema is the JSON Schema of the v2 envelope.
+//
+//go:embed gobid.v2.schema.json
+var ProtocolV2Schema []byte
+
+var ErrUnknownMessageType = errors.New("unknown message type")
+
+// Protocol is a wire format of the websocket messages.
+type Protocol interface {
+	Decode(payload []byte) (Message, error)
+	Encode(m Message) ([]byte, error)
+}
+
+// ProtocolFor picks the protocol negotiated as subprotocol.
+func ProtocolFor(subprotocol string) Protocol {
+	if subprotocol == SubprotocolV2 {
+		return protocolV2{}
+	}
+	return protocolV1{}
+}
+
+// protocolV1 sends Message as it is, identifying it by its numeric kind.
+type protocolV1 struct{}
+
+func (protocolV1) Decode(payload []byte) (Message, error) {
+	var m Message
+	err := json.Unmarshal(payload, &m)
+	return m, err
+}
+
+func (protocolV1) Encode(m Message) ([]byte, error) {
+	return json.Marshal(m)
+}
+
+var kindTypes = map[Kind]string{
+	PlaceBid:              "place_bid",
+	SuccessfullyPlacedBid: "bid_accepted",
+	NewBidPlaced:          "bid_placed",
+	AuctionFinished:       "auction_finished",
+	FailedToPlaceBid:      "request_failed",
+	InvalidBody:           "invalid_message",
+	AuctionSettled:        "auction_settled",
+	AuctionExtended:       "auction_extended",
+	BuyNow:                "buy_now",
+	BoughtNow:             "bought_now",
+	AcceptPrice:           "accept_price",
+	PriceDropped:          "price_dropped",
+	AuctionCancelled:      "auction_cancelled",
+	RetractBid:            "retract_bid",
+	BidRetracted:          "bid_retracted",
+	PriceCorrected:        "price_corrected",
+	Snapshot:              "snapshot",
+	Tick:                  "tick",
+	TimeSync:              "time_sync",
+	TimeSynced:            "time_synced",
+}
+
+var typeKinds = func() map[string]Kind {
+	kinds := make(map[string]Kind, len(kindTypes))
+	for kind, t := range kindTypes {
+		kinds[t] = kind
+	}
+	return kinds
+}()
+
+// String is the v2 type of the kind.
+func (k Kind) String() string {
+	return kindTypes[k]
+}
+
+// envelopeV2 is the v2 wire format: a Message named by a string type, with
+// the request id of the client echoed in the replies to it.
+type envelopeV2 struct {
+	Version   int    `json:"v"`
+	Type      string `json:"type"`
+	RequestId string `json:"request_id,omitempty"`
+
+	// Kind hides the numeric kind of the embedded Message.
+	Kind *Kind `json:"kind,omitempty"`
+	*Message
+}
+
+type protocolV2 struct{}
+
+func (protocolV2) Decode(payload []byte) (Message, error) {
+	var m Message
+	envelope := envelopeV2{Message: &m}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return Message{}, err
+	}
+
+	m.RequestId = envelope.RequestId
+	kind, ok := typeKinds[envelope.Type]
+	if !ok {
+		return m, ErrUnknownMessageType
+	}
+	m.Kind = kind
+
+	return m, nil
+}
+
+func (protocolV2) Encode(m Message) ([]byte, error) {
+	return json.Marshal(envelopeV2{
+		Version:   2,
+		Type:      m.Kind.String(),
+		RequestId: m.RequestId,
+		Message:   &m,
+	})
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/JoaoRafa19/gobid/internal/usecase/product"
+	"github.com/google/uuid"
+)
+
+func isSealed(p pgstore.Product) bool {
+	return p.AuctionType == product.AuctionTypeSealedFirstPrice ||
+		p.AuctionType == product.AuctionTypeSealedSecondPrice
+}
+
+// placeSealedBid stores a bid without comparing it to the others, since
+// bidders in a sealed auction never learn about each other's bids.
+func (b *BidsService) placeSealedBid(ctx context.Context, queries *pgstore.Queries, p pgstore.Product, bidder uuid.UUID, amount, maxAmount money.Amount) (PlacedBid, error) {
+	if maxAmount != 0 {
+		return PlacedBid{}, ErrUnsupportedAuctionType
+	}
+	if amount < p.BasePrice {
+		return PlacedBid{}, ErrBidIsTooLow
+	}
+
+	bid, err := queries.CreateBid(ctx, pgstore.CreateBidParams{
+		ProductID: p.ID,
+		BidderID:  bidder,
+		Amount:    amount,
+		Currency:  p.Currency,
+	})
+	if err != nil {
+		return PlacedBid{}, err
+	}
+
+	return PlacedBid{
+		Bid:        bid,
+		AuctionEnd: p.AuctionEnd,
+		Sealed:     true,
+	}, nil
+}
+
+// winningPrice is what the top bidder pays. Sealed second price (Vickrey)
+// auctions charge the runner-up bid, never below the base or reserve price;
+// every other auction charges the winning bid itself.
+func winningPrice(p pgstore.Product, ranking []pgstore.Bid) money.Amount {
+	if p.AuctionType != product.AuctionTypeSealedSecondPrice {
+		return ranking[0].Amount
+	}
+
+	price := max(p.BasePrice, p.ReservePrice)
+	if len(ranking) > 1 {
+		price = max(price, ranking[1].Amount)
+	}
+	return min(price, ranking[0].Amount)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/store/pgstore"
+	"github.com/JoaoRafa19/gobid/internal/usecase/product"
+	"testing"
+)
+
+func rankedBids(amounts ...money.Amount) []pgstore.Bid {
+	bids := make([]pgstore.Bid, 0, len(amounts))
+	for _, amount := range amounts {
+		bids = append(bids, pgstore.Bid{Amount: amount})
+	}
+	return bids
+}
+
+func TestWinningPrice(t *testing.T) {
+	tests := []struct {
+		name         string
+		auctionType  string
+		basePrice    money.Amount
+		reservePrice money.Amount
+		ranking      []pgstore.Bid
+		want         money.Amount
+	}{
+		{
+			name:        "first price charges the winning bid",
+			auctionType: product.AuctionTypeSealedFirstPrice,
+			basePrice:   100,
+			ranking:     rankedBids(300, 250),
+			want:        300,
+		},
+		{
+			name:        "english charges the winning bid",
+			auctionType: product.AuctionTypeEnglish,
+			basePrice:   100,
+			ranking:     rankedBids(300, 250),
+			want:        300,
+		},
+		{
+			name:        "second price charges the runner-up",
+			auctionType: product.AuctionTypeSealedSecondPrice,
+			basePrice:   100,
+			ranking:     rankedBids(300, 250, 120),
+			want:        250,
+		},
+		{
+			name:        "second price single bid charges the base price",
+			auctionType: product.AuctionTypeSealedSecondPrice,
+			basePrice:   100,
+			ranking:     rankedBids(300),
+			want:        100,
+		},
+		{
+			name:         "second price reserve above the runner-up",
+			auctionType:  product.AuctionTypeSealedSecondPrice,
+			basePrice:    100,
+			reservePrice: 200,
+			ranking:      rankedBids(300, 150),
+			want:         200,
+		},
+		{
+			name:        "second price tie charges the tied bid",
+			auctionType: product.AuctionTypeSealedSecondPrice,
+			basePrice:   100,
+			ranking:     rankedBids(300, 300),
+			want:        300,
+		},
+		{
+			name:         "second price is capped at the winning bid",
+			auctionType:  product.AuctionTypeSealedSecondPrice,
+			basePrice:    100,
+			reservePrice: 400,
+			ranking:      rankedBids(300, 150),
+			want:         300,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := pgstore.Product{
+				AuctionType:  tt.auctionType,
+				BasePrice:    tt.basePrice,
+				ReservePrice: tt.reservePrice,
+			}
+			if got := winningPrice(p, tt.ranking); got != tt.want {
+				t.Errorf("winningPrice() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
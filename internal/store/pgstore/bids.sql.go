@@ -8,23 +8,30 @@ package pgstore
 import (
 	"context"
 
+	"github.com/JoaoRafa19/gobid/internal/money"
 	"github.com/google/uuid"
 )
 
 const createBid = `-- name: CreateBid :one
 INSERT INTO bids (
-                  product_id, bidder_id, amount
-) VALUES ( $1, $2, $3 ) returning id, product_id, bidder_id, created_at, amount
+                  product_id, bidder_id, amount, currency
+) VALUES ( $1, $2, $3, $4 ) returning id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency
 `
 
 type CreateBidParams struct {
-	ProductID uuid.UUID `json:"product_id"`
-	BidderID  uuid.UUID `json:"bidder_id"`
-	Amount    float64   `json:"amount"`
+	ProductID uuid.UUID    `json:"product_id"`
+	BidderID  uuid.UUID    `json:"bidder_id"`
+	Amount    money.Amount `json:"amount"`
+	Currency  string       `json:"currency"`
 }
 
 func (q *Queries) CreateBid(ctx context.Context, arg CreateBidParams) (Bid, error) {
-	row := q.db.QueryRow(ctx, createBid, arg.ProductID, arg.BidderID, arg.Amount)
+	row := q.db.QueryRow(ctx, createBid,
+		arg.ProductID,
+		arg.BidderID,
+		arg.Amount,
+		arg.Currency,
+	)
 	var i Bid
 	err := row.Scan(
 		&i.ID,
@@ -32,13 +39,16 @@ func (q *Queries) CreateBid(ctx context.Context, arg CreateBidParams) (Bid, erro
 		&i.BidderID,
 		&i.CreatedAt,
 		&i.Amount,
+		&i.Voided,
+		&i.RetractedAt,
+		&i.Currency,
 	)
 	return i, err
 }
 
 const getBidsByProductId = `-- name: GetBidsByProductId :many
-SELECT id, product_id, bidder_id, created_at, amount FROM bids
-WHERE product_id = $1
+SELECT id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency FROM bids
+WHERE product_id = $1 AND retracted_at IS NULL AND NOT voided
 ORDER BY amount DESC
 LIMIT 10
 `
@@ -58,6 +68,9 @@ func (q *Queries) GetBidsByProductId(ctx context.Context, productID uuid.UUID) (
 			&i.BidderID,
 			&i.CreatedAt,
 			&i.Amount,
+			&i.Voided,
+			&i.RetractedAt,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -70,8 +83,8 @@ func (q *Queries) GetBidsByProductId(ctx context.Context, productID uuid.UUID) (
 }
 
 const getHighestBidByProductId = `-- name: GetHighestBidByProductId :many
-SELECT id, product_id, bidder_id, created_at, amount FROM bids
-WHERE product_id = $1
+SELECT id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency FROM bids
+WHERE product_id = $1 AND retracted_at IS NULL AND NOT voided
 ORDER BY amount DESC
 LIMIT 1
 `
@@ -91,6 +104,77 @@ func (q *Queries) GetHighestBidByProductId(ctx context.Context, productID uuid.U
 			&i.BidderID,
 			&i.CreatedAt,
 			&i.Amount,
+			&i.Voided,
+			&i.RetractedAt,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestBidByBidder = `-- name: GetLatestBidByBidder :one
+SELECT id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency FROM bids
+WHERE product_id = $1 AND bidder_id = $2 AND retracted_at IS NULL AND NOT voided
+ORDER BY created_at DESC
+LIMIT 1
+`
+
+type GetLatestBidByBidderParams struct {
+	ProductID uuid.UUID `json:"product_id"`
+	BidderID  uuid.UUID `json:"bidder_id"`
+}
+
+func (q *Queries) GetLatestBidByBidder(ctx context.Context, arg GetLatestBidByBidderParams) (Bid, error) {
+	row := q.db.QueryRow(ctx, getLatestBidByBidder, arg.ProductID, arg.BidderID)
+	var i Bid
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.BidderID,
+		&i.CreatedAt,
+		&i.Amount,
+		&i.Voided,
+		&i.RetractedAt,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getTopSealedBidsByProductId = `-- name: GetTopSealedBidsByProductId :many
+SELECT id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency FROM (
+    SELECT DISTINCT ON (bidder_id) id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency
+    FROM bids
+    WHERE product_id = $1 AND retracted_at IS NULL AND NOT voided
+    ORDER BY bidder_id, amount DESC, created_at ASC
+) AS top_bids
+ORDER BY amount DESC, created_at ASC
+LIMIT 2
+`
+
+func (q *Queries) GetTopSealedBidsByProductId(ctx context.Context, productID uuid.UUID) ([]Bid, error) {
+	rows, err := q.db.Query(ctx, getTopSealedBidsByProductId, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Bid
+	for rows.Next() {
+		var i Bid
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.BidderID,
+			&i.CreatedAt,
+			&i.Amount,
+			&i.Voided,
+			&i.RetractedAt,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -101,3 +185,34 @@ func (q *Queries) GetHighestBidByProductId(ctx context.Context, productID uuid.U
 	}
 	return items, nil
 }
+
+const retractBid = `-- name: RetractBid :one
+UPDATE bids SET retracted_at = now()
+WHERE id = $1
+RETURNING id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency
+`
+
+func (q *Queries) RetractBid(ctx context.Context, id uuid.UUID) (Bid, error) {
+	row := q.db.QueryRow(ctx, retractBid, id)
+	var i Bid
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.BidderID,
+		&i.CreatedAt,
+		&i.Amount,
+		&i.Voided,
+		&i.RetractedAt,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const voidBidsByProductId = `-- name: VoidBidsByProductId :exec
+UPDATE bids SET voided = true WHERE product_id = $1
+`
+
+func (q *Queries) VoidBidsByProductId(ctx context.Context, productID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, voidBidsByProductId, productID)
+	return err
+}
@@ -0,0 +1,15 @@
+-- Write your migrate up statements here
+CREATE TABLE IF NOT EXISTS auction_results (
+    product_id UUID PRIMARY KEY REFERENCES products(id),
+    status TEXT NOT NULL,
+    winning_bid_id UUID REFERENCES bids(id),
+    winner_id UUID REFERENCES users(id),
+    final_price FLOAT,
+    settled_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS auction_results;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,12 @@
+-- Write your migrate up statements here
+ALTER TABLE products
+    ADD COLUMN soft_close_minutes INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN extension_minutes INTEGER NOT NULL DEFAULT 0;
+---- create above / drop below ----
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS soft_close_minutes,
+    DROP COLUMN IF EXISTS extension_minutes;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
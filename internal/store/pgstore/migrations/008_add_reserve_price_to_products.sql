@@ -0,0 +1,10 @@
+-- Write your migrate up statements here
+ALTER TABLE products
+    ADD COLUMN reserve_price FLOAT NOT NULL DEFAULT 0;
+---- create above / drop below ----
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS reserve_price;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
@@ -0,0 +1,18 @@
+-- Write your migrate up statements here
+ALTER TABLE products
+    ADD COLUMN auction_type TEXT NOT NULL DEFAULT 'english',
+    ADD COLUMN price_floor FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN price_drop FLOAT NOT NULL DEFAULT 0,
+    ADD COLUMN price_drop_seconds INTEGER NOT NULL DEFAULT 0,
+    ADD CONSTRAINT products_auction_type_check CHECK (auction_type IN ('english', 'dutch'));
+---- create above / drop below ----
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_auction_type_check,
+    DROP COLUMN IF EXISTS auction_type,
+    DROP COLUMN IF EXISTS price_floor,
+    DROP COLUMN IF EXISTS price_drop,
+    DROP COLUMN IF EXISTS price_drop_seconds;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
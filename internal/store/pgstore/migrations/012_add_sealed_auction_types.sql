@@ -0,0 +1,14 @@
+-- Write your migrate up statements here
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_auction_type_check,
+    ADD CONSTRAINT products_auction_type_check CHECK (
+        auction_type IN ('english', 'dutch', 'sealed_first_price', 'sealed_second_price')
+    );
+---- create above / drop below ----
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_auction_type_check,
+    ADD CONSTRAINT products_auction_type_check CHECK (auction_type IN ('english', 'dutch'));
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
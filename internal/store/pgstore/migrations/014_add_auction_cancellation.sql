@@ -0,0 +1,16 @@
+-- Write your migrate up statements here
+ALTER TABLE auction_results
+    ADD COLUMN cancel_reason TEXT;
+
+ALTER TABLE bids
+    ADD COLUMN voided BOOLEAN NOT NULL DEFAULT FALSE;
+---- create above / drop below ----
+
+ALTER TABLE bids
+    DROP COLUMN IF EXISTS voided;
+
+ALTER TABLE auction_results
+    DROP COLUMN IF EXISTS cancel_reason;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
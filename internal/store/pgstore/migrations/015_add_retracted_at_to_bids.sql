@@ -0,0 +1,10 @@
+-- Write your migrate up statements here
+ALTER TABLE bids
+    ADD COLUMN retracted_at TIMESTAMPTZ;
+---- create above / drop below ----
+
+ALTER TABLE bids
+    DROP COLUMN IF EXISTS retracted_at;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
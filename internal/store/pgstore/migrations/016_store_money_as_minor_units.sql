@@ -0,0 +1,38 @@
+-- Write your migrate up statements here
+ALTER TABLE products
+    ALTER COLUMN base_price TYPE BIGINT USING round(base_price * 100)::BIGINT,
+    ALTER COLUMN reserve_price TYPE BIGINT USING round(reserve_price * 100)::BIGINT,
+    ALTER COLUMN buy_now_price TYPE BIGINT USING round(buy_now_price * 100)::BIGINT,
+    ALTER COLUMN bid_increment TYPE BIGINT USING round(bid_increment * 100)::BIGINT,
+    ALTER COLUMN price_floor TYPE BIGINT USING round(price_floor * 100)::BIGINT,
+    ALTER COLUMN price_drop TYPE BIGINT USING round(price_drop * 100)::BIGINT;
+
+ALTER TABLE bids
+    ALTER COLUMN amount TYPE BIGINT USING round(amount * 100)::BIGINT;
+
+ALTER TABLE max_bids
+    ALTER COLUMN max_amount TYPE BIGINT USING round(max_amount * 100)::BIGINT;
+
+ALTER TABLE auction_results
+    ALTER COLUMN final_price TYPE BIGINT USING round(final_price * 100)::BIGINT;
+---- create above / drop below ----
+
+ALTER TABLE auction_results
+    ALTER COLUMN final_price TYPE FLOAT USING final_price / 100.0;
+
+ALTER TABLE max_bids
+    ALTER COLUMN max_amount TYPE FLOAT USING max_amount / 100.0;
+
+ALTER TABLE bids
+    ALTER COLUMN amount TYPE FLOAT USING amount / 100.0;
+
+ALTER TABLE products
+    ALTER COLUMN base_price TYPE FLOAT USING base_price / 100.0,
+    ALTER COLUMN reserve_price TYPE FLOAT USING reserve_price / 100.0,
+    ALTER COLUMN buy_now_price TYPE FLOAT USING buy_now_price / 100.0,
+    ALTER COLUMN bid_increment TYPE FLOAT USING bid_increment / 100.0,
+    ALTER COLUMN price_floor TYPE FLOAT USING price_floor / 100.0,
+    ALTER COLUMN price_drop TYPE FLOAT USING price_drop / 100.0;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
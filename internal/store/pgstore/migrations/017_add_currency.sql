@@ -0,0 +1,36 @@
+-- Write your migrate up statements here
+ALTER TABLE products
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'BRL',
+    ADD CONSTRAINT products_currency_check CHECK (currency IN ('BRL', 'USD', 'EUR'));
+
+ALTER TABLE bids
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'BRL';
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+    base_currency TEXT NOT NULL,
+    quote_currency TEXT NOT NULL,
+    rate NUMERIC(18, 8) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (base_currency, quote_currency)
+);
+
+INSERT INTO fx_rates (base_currency, quote_currency, rate) VALUES
+    ('BRL', 'USD', 0.18),
+    ('BRL', 'EUR', 0.17),
+    ('USD', 'BRL', 5.50),
+    ('USD', 'EUR', 0.92),
+    ('EUR', 'BRL', 6.00),
+    ('EUR', 'USD', 1.09);
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS fx_rates;
+
+ALTER TABLE bids
+    DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE products
+    DROP CONSTRAINT IF EXISTS products_currency_check,
+    DROP COLUMN IF EXISTS currency;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
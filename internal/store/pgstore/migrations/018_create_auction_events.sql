@@ -0,0 +1,20 @@
+-- Write your migrate up statements here
+ALTER TABLE products
+    ADD COLUMN event_seq BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS auction_events (
+    product_id UUID NOT NULL REFERENCES products(id),
+    seq BIGINT NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (product_id, seq)
+);
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS auction_events;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS event_seq;
+
+-- Write your migrate down statements here. If this migration is irreversible
+-- Then delete the separator line above.
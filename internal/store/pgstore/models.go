@@ -7,27 +7,77 @@ package pgstore
 import (
 	"time"
 
+	"github.com/JoaoRafa19/gobid/internal/money"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-type Bid struct {
-	ID        uuid.UUID `json:"id"`
+type AuctionEvent struct {
 	ProductID uuid.UUID `json:"product_id"`
-	BidderID  uuid.UUID `json:"bidder_id"`
+	Seq       int64     `json:"seq"`
+	Payload   []byte    `json:"payload"`
 	CreatedAt time.Time `json:"created_at"`
-	Amount    float64   `json:"amount"`
+}
+
+type AuctionResult struct {
+	ProductID    uuid.UUID   `json:"product_id"`
+	Status       string      `json:"status"`
+	WinningBidID pgtype.UUID `json:"winning_bid_id"`
+	WinnerID     pgtype.UUID `json:"winner_id"`
+	FinalPrice   pgtype.Int8 `json:"final_price"`
+	SettledAt    time.Time   `json:"settled_at"`
+	CancelReason pgtype.Text `json:"cancel_reason"`
+}
+
+type Bid struct {
+	ID          uuid.UUID          `json:"id"`
+	ProductID   uuid.UUID          `json:"product_id"`
+	BidderID    uuid.UUID          `json:"bidder_id"`
+	CreatedAt   time.Time          `json:"created_at"`
+	Amount      money.Amount       `json:"amount"`
+	Voided      bool               `json:"voided"`
+	RetractedAt pgtype.Timestamptz `json:"retracted_at"`
+	Currency    string             `json:"currency"`
+}
+
+type FxRate struct {
+	BaseCurrency  string         `json:"base_currency"`
+	QuoteCurrency string         `json:"quote_currency"`
+	Rate          pgtype.Numeric `json:"rate"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+type MaxBid struct {
+	ID        uuid.UUID    `json:"id"`
+	ProductID uuid.UUID    `json:"product_id"`
+	BidderID  uuid.UUID    `json:"bidder_id"`
+	MaxAmount money.Amount `json:"max_amount"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
 }
 
 type Product struct {
-	ID          uuid.UUID `json:"id"`
-	SellerID    uuid.UUID `json:"seller_id"`
-	ProductName string    `json:"product_name"`
-	Description string    `json:"description"`
-	BasePrice   float64   `json:"base_price"`
-	AuctionEnd  time.Time `json:"auction_end"`
-	Sold        bool      `json:"sold"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID               uuid.UUID    `json:"id"`
+	SellerID         uuid.UUID    `json:"seller_id"`
+	ProductName      string       `json:"product_name"`
+	Description      string       `json:"description"`
+	BasePrice        money.Amount `json:"base_price"`
+	AuctionEnd       time.Time    `json:"auction_end"`
+	Sold             bool         `json:"sold"`
+	CreatedAt        time.Time    `json:"created_at"`
+	UpdatedAt        time.Time    `json:"updated_at"`
+	SoftCloseMinutes int32        `json:"soft_close_minutes"`
+	ExtensionMinutes int32        `json:"extension_minutes"`
+	ReservePrice     money.Amount `json:"reserve_price"`
+	BuyNowPrice      money.Amount `json:"buy_now_price"`
+	BidIncrement     money.Amount `json:"bid_increment"`
+	AuctionType      string       `json:"auction_type"`
+	PriceFloor       money.Amount `json:"price_floor"`
+	PriceDrop        money.Amount `json:"price_drop"`
+	PriceDropSeconds int32        `json:"price_drop_seconds"`
+	AuctionStart     time.Time    `json:"auction_start"`
+	Currency         string       `json:"currency"`
+	EventSeq         int64        `json:"event_seq"`
 }
 
 type Session struct {
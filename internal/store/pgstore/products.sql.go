@@ -9,9 +9,19 @@ import (
 	"context"
 	"time"
 
+	"github.com/JoaoRafa19/gobid/internal/money"
 	"github.com/google/uuid"
 )
 
+const closeAuction = `-- name: CloseAuction :exec
+UPDATE products SET auction_end = now(), updated_at = now() WHERE id = $1
+`
+
+func (q *Queries) CloseAuction(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, closeAuction, id)
+	return err
+}
+
 const createProduct = `-- name: CreateProduct :one
 INSERT INTO products
     (
@@ -19,34 +29,107 @@ INSERT INTO products
      product_name,
      description,
      base_price,
-     auction_end
+     auction_end,
+     soft_close_minutes,
+     extension_minutes,
+     reserve_price,
+     buy_now_price,
+     bid_increment,
+     auction_type,
+     price_floor,
+     price_drop,
+     price_drop_seconds,
+     auction_start,
+     currency
     )
-VALUES ($1, $2 , $3, $4, $5) returning id
+VALUES ($1, $2 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) returning id, seller_id, product_name, description, base_price, auction_end, sold, created_at, updated_at, soft_close_minutes, extension_minutes, reserve_price, buy_now_price, bid_increment, auction_type, price_floor, price_drop, price_drop_seconds, auction_start, currency, event_seq
 `
 
 type CreateProductParams struct {
-	SellerID    uuid.UUID `json:"seller_id"`
-	ProductName string    `json:"product_name"`
-	Description string    `json:"description"`
-	BasePrice   float64   `json:"base_price"`
-	AuctionEnd  time.Time `json:"auction_end"`
+	SellerID         uuid.UUID    `json:"seller_id"`
+	ProductName      string       `json:"product_name"`
+	Description      string       `json:"description"`
+	BasePrice        money.Amount `json:"base_price"`
+	AuctionEnd       time.Time    `json:"auction_end"`
+	SoftCloseMinutes int32        `json:"soft_close_minutes"`
+	ExtensionMinutes int32        `json:"extension_minutes"`
+	ReservePrice     money.Amount `json:"reserve_price"`
+	BuyNowPrice      money.Amount `json:"buy_now_price"`
+	BidIncrement     money.Amount `json:"bid_increment"`
+	AuctionType      string       `json:"auction_type"`
+	PriceFloor       money.Amount `json:"price_floor"`
+	PriceDrop        money.Amount `json:"price_drop"`
+	PriceDropSeconds int32        `json:"price_drop_seconds"`
+	AuctionStart     time.Time    `json:"auction_start"`
+	Currency         string       `json:"currency"`
 }
 
-func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (uuid.UUID, error) {
+func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
 	row := q.db.QueryRow(ctx, createProduct,
 		arg.SellerID,
 		arg.ProductName,
 		arg.Description,
 		arg.BasePrice,
 		arg.AuctionEnd,
+		arg.SoftCloseMinutes,
+		arg.ExtensionMinutes,
+		arg.ReservePrice,
+		arg.BuyNowPrice,
+		arg.BidIncrement,
+		arg.AuctionType,
+		arg.PriceFloor,
+		arg.PriceDrop,
+		arg.PriceDropSeconds,
+		arg.AuctionStart,
+		arg.Currency,
 	)
-	var id uuid.UUID
-	err := row.Scan(&id)
-	return id, err
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.SellerID,
+		&i.ProductName,
+		&i.Description,
+		&i.BasePrice,
+		&i.AuctionEnd,
+		&i.Sold,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SoftCloseMinutes,
+		&i.ExtensionMinutes,
+		&i.ReservePrice,
+		&i.BuyNowPrice,
+		&i.BidIncrement,
+		&i.AuctionType,
+		&i.PriceFloor,
+		&i.PriceDrop,
+		&i.PriceDropSeconds,
+		&i.AuctionStart,
+		&i.Currency,
+		&i.EventSeq,
+	)
+	return i, err
+}
+
+const extendAuctionEnd = `-- name: ExtendAuctionEnd :one
+UPDATE products SET auction_end = $2, updated_at = now()
+WHERE id = $1
+RETURNING auction_end
+`
+
+type ExtendAuctionEndParams struct {
+	ID         uuid.UUID `json:"id"`
+	AuctionEnd time.Time `json:"auction_end"`
+}
+
+func (q *Queries) ExtendAuctionEnd(ctx context.Context, arg ExtendAuctionEndParams) (time.Time, error) {
+	row := q.db.QueryRow(ctx, extendAuctionEnd, arg.ID, arg.AuctionEnd)
+	var auction_end time.Time
+	err := row.Scan(&auction_end)
+	return auction_end, err
 }
 
 const getProductById = `-- name: GetProductById :one
-SELECT id, seller_id, product_name, description, base_price, auction_end, sold, created_at, updated_at FROM products WHERE id = $1
+SELECT id, seller_id, product_name, description, base_price, auction_end, sold, created_at, updated_at, soft_close_minutes, extension_minutes, reserve_price, buy_now_price, bid_increment, auction_type, price_floor, price_drop, price_drop_seconds, auction_start, currency, event_seq FROM products WHERE id = $1
 `
 
 func (q *Queries) GetProductById(ctx context.Context, id uuid.UUID) (Product, error) {
@@ -62,6 +145,173 @@ func (q *Queries) GetProductById(ctx context.Context, id uuid.UUID) (Product, er
 		&i.Sold,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.SoftCloseMinutes,
+		&i.ExtensionMinutes,
+		&i.ReservePrice,
+		&i.BuyNowPrice,
+		&i.BidIncrement,
+		&i.AuctionType,
+		&i.PriceFloor,
+		&i.PriceDrop,
+		&i.PriceDropSeconds,
+		&i.AuctionStart,
+		&i.Currency,
+		&i.EventSeq,
 	)
 	return i, err
 }
+
+const getProductByIdForUpdate = `-- name: GetProductByIdForUpdate :one
+SELECT id, seller_id, product_name, description, base_price, auction_end, sold, created_at, updated_at, soft_close_minutes, extension_minutes, reserve_price, buy_now_price, bid_increment, auction_type, price_floor, price_drop, price_drop_seconds, auction_start, currency, event_seq FROM products WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) GetProductByIdForUpdate(ctx context.Context, id uuid.UUID) (Product, error) {
+	row := q.db.QueryRow(ctx, getProductByIdForUpdate, id)
+	var i Product
+	err := row.Scan(
+		&i.ID,
+		&i.SellerID,
+		&i.ProductName,
+		&i.Description,
+		&i.BasePrice,
+		&i.AuctionEnd,
+		&i.Sold,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.SoftCloseMinutes,
+		&i.ExtensionMinutes,
+		&i.ReservePrice,
+		&i.BuyNowPrice,
+		&i.BidIncrement,
+		&i.AuctionType,
+		&i.PriceFloor,
+		&i.PriceDrop,
+		&i.PriceDropSeconds,
+		&i.AuctionStart,
+		&i.Currency,
+		&i.EventSeq,
+	)
+	return i, err
+}
+
+const isAuctionOpen = `-- name: IsAuctionOpen :one
+SELECT EXISTS (
+    SELECT 1 FROM products
+    WHERE id = $1 AND sold = false AND auction_end > now()
+      AND NOT EXISTS (SELECT 1 FROM auction_results WHERE auction_results.product_id = products.id)
+)
+`
+
+func (q *Queries) IsAuctionOpen(ctx context.Context, id uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isAuctionOpen, id)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listOpenAuctions = `-- name: ListOpenAuctions :many
+SELECT id, seller_id, product_name, description, base_price, auction_end, sold, created_at, updated_at, soft_close_minutes, extension_minutes, reserve_price, buy_now_price, bid_increment, auction_type, price_floor, price_drop, price_drop_seconds, auction_start, currency, event_seq FROM products
+WHERE sold = false AND auction_end > now()
+  AND NOT EXISTS (SELECT 1 FROM auction_results WHERE auction_results.product_id = products.id)
+ORDER BY auction_end
+`
+
+func (q *Queries) ListOpenAuctions(ctx context.Context) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listOpenAuctions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.SellerID,
+			&i.ProductName,
+			&i.Description,
+			&i.BasePrice,
+			&i.AuctionEnd,
+			&i.Sold,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SoftCloseMinutes,
+			&i.ExtensionMinutes,
+			&i.ReservePrice,
+			&i.BuyNowPrice,
+			&i.BidIncrement,
+			&i.AuctionType,
+			&i.PriceFloor,
+			&i.PriceDrop,
+			&i.PriceDropSeconds,
+			&i.AuctionStart,
+			&i.Currency,
+			&i.EventSeq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnsettledAuctions = `-- name: ListUnsettledAuctions :many
+SELECT id, seller_id, product_name, description, base_price, auction_end, sold, created_at, updated_at, soft_close_minutes, extension_minutes, reserve_price, buy_now_price, bid_increment, auction_type, price_floor, price_drop, price_drop_seconds, auction_start, currency, event_seq FROM products
+WHERE sold = false AND auction_end <= now()
+  AND NOT EXISTS (SELECT 1 FROM auction_results WHERE auction_results.product_id = products.id)
+ORDER BY auction_end
+`
+
+func (q *Queries) ListUnsettledAuctions(ctx context.Context) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listUnsettledAuctions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.SellerID,
+			&i.ProductName,
+			&i.Description,
+			&i.BasePrice,
+			&i.AuctionEnd,
+			&i.Sold,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.SoftCloseMinutes,
+			&i.ExtensionMinutes,
+			&i.ReservePrice,
+			&i.BuyNowPrice,
+			&i.BidIncrement,
+			&i.AuctionType,
+			&i.PriceFloor,
+			&i.PriceDrop,
+			&i.PriceDropSeconds,
+			&i.AuctionStart,
+			&i.Currency,
+			&i.EventSeq,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markProductAsSold = `-- name: MarkProductAsSold :exec
+UPDATE products SET sold = true, updated_at = now() WHERE id = $1
+`
+
+func (q *Queries) MarkProductAsSold(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, markProductAsSold, id)
+	return err
+}
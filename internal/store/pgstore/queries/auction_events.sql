@@ -0,0 +1,17 @@
+-- name: AppendAuctionEvent :one
+WITH next AS (
+    UPDATE products SET event_seq = event_seq + 1
+    WHERE id = $1
+    RETURNING event_seq
+)
+INSERT INTO auction_events (product_id, seq, payload)
+SELECT $1, event_seq, $2 FROM next
+RETURNING seq;
+
+-- name: ListAuctionEventsAfter :many
+SELECT seq, payload FROM auction_events
+WHERE product_id = $1 AND seq > $2
+ORDER BY seq;
+
+-- name: NotifyAuctionEvent :exec
+SELECT pg_notify(sqlc.arg(channel)::TEXT, sqlc.arg(payload)::TEXT);
@@ -0,0 +1,7 @@
+-- name: CreateAuctionResult :one
+INSERT INTO auction_results (
+    product_id, status, winning_bid_id, winner_id, final_price, cancel_reason
+) VALUES ( $1, $2, $3, $4, $5, $6 ) returning *;
+
+-- name: GetAuctionResultByProductId :one
+SELECT * FROM auction_results WHERE product_id = $1;
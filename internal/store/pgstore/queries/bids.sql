@@ -1,16 +1,40 @@
 -- name: CreateBid :one
 INSERT INTO bids (
-                  product_id, bidder_id, amount
-) VALUES ( $1, $2, $3 ) returning *;
+                  product_id, bidder_id, amount, currency
+) VALUES ( $1, $2, $3, $4 ) returning *;
 
 -- name: GetBidsByProductId :many
 SELECT * FROM bids
-WHERE product_id = $1
+WHERE product_id = $1 AND retracted_at IS NULL AND NOT voided
 ORDER BY amount DESC
 LIMIT 10;
 
 -- name: GetHighestBidByProductId :many
 SELECT * FROM bids
-WHERE product_id = $1
+WHERE product_id = $1 AND retracted_at IS NULL AND NOT voided
 ORDER BY amount DESC
-LIMIT 1;
\ No newline at end of file
+LIMIT 1;
+
+-- name: GetLatestBidByBidder :one
+SELECT * FROM bids
+WHERE product_id = $1 AND bidder_id = $2 AND retracted_at IS NULL AND NOT voided
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: RetractBid :one
+UPDATE bids SET retracted_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: VoidBidsByProductId :exec
+UPDATE bids SET voided = true WHERE product_id = $1;
+
+-- name: GetTopSealedBidsByProductId :many
+SELECT id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency FROM (
+    SELECT DISTINCT ON (bidder_id) id, product_id, bidder_id, created_at, amount, voided, retracted_at, currency
+    FROM bids
+    WHERE product_id = $1 AND retracted_at IS NULL AND NOT voided
+    ORDER BY bidder_id, amount DESC, created_at ASC
+) AS top_bids
+ORDER BY amount DESC, created_at ASC
+LIMIT 2;
@@ -0,0 +1,5 @@
+-- name: ListReferencePrices :many
+SELECT quote_currency, round(sqlc.arg(amount)::BIGINT * rate)::BIGINT AS amount
+FROM fx_rates
+WHERE base_currency = sqlc.arg(currency)
+ORDER BY quote_currency;
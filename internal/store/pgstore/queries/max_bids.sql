@@ -0,0 +1,16 @@
+-- name: UpsertMaxBid :exec
+INSERT INTO max_bids (
+                      product_id, bidder_id, max_amount
+) VALUES ( $1, $2, $3 )
+ON CONFLICT (product_id, bidder_id) DO UPDATE
+SET max_amount = EXCLUDED.max_amount, updated_at = now()
+WHERE max_bids.max_amount < EXCLUDED.max_amount;
+
+-- name: GetTopMaxBidsByProductId :many
+SELECT * FROM max_bids
+WHERE product_id = $1
+ORDER BY max_amount DESC, updated_at ASC
+LIMIT 2;
+
+-- name: DeleteMaxBid :exec
+DELETE FROM max_bids WHERE product_id = $1 AND bidder_id = $2;
@@ -5,10 +5,54 @@ INSERT INTO products
      product_name,
      description,
      base_price,
-     auction_end
+     auction_end,
+     soft_close_minutes,
+     extension_minutes,
+     reserve_price,
+     buy_now_price,
+     bid_increment,
+     auction_type,
+     price_floor,
+     price_drop,
+     price_drop_seconds,
+     auction_start,
+     currency
     )
-VALUES ($1, $2 , $3, $4, $5) returning id;
+VALUES ($1, $2 , $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16) returning *;
 
 
 -- name: GetProductById :one
-SELECT * FROM products WHERE id = $1;
\ No newline at end of file
+SELECT * FROM products WHERE id = $1;
+
+-- name: GetProductByIdForUpdate :one
+SELECT * FROM products WHERE id = $1 FOR UPDATE;
+
+-- name: ExtendAuctionEnd :one
+UPDATE products SET auction_end = $2, updated_at = now()
+WHERE id = $1
+RETURNING auction_end;
+
+-- name: IsAuctionOpen :one
+SELECT EXISTS (
+    SELECT 1 FROM products
+    WHERE id = $1 AND sold = false AND auction_end > now()
+      AND NOT EXISTS (SELECT 1 FROM auction_results WHERE auction_results.product_id = products.id)
+);
+
+-- name: ListOpenAuctions :many
+SELECT * FROM products
+WHERE sold = false AND auction_end > now()
+  AND NOT EXISTS (SELECT 1 FROM auction_results WHERE auction_results.product_id = products.id)
+ORDER BY auction_end;
+
+-- name: ListUnsettledAuctions :many
+SELECT * FROM products
+WHERE sold = false AND auction_end <= now()
+  AND NOT EXISTS (SELECT 1 FROM auction_results WHERE auction_results.product_id = products.id)
+ORDER BY auction_end;
+
+-- name: MarkProductAsSold :exec
+UPDATE products SET sold = true, updated_at = now() WHERE id = $1;
+
+-- name: CloseAuction :exec
+UPDATE products SET auction_end = now(), updated_at = now() WHERE id = $1;
@@ -0,0 +1,19 @@
+package product
+
+import (
+	"context"
+	"github.com/JoaoRafa19/gobid/internal/validator"
+)
+
+type CancelAuctionRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (c CancelAuctionRequest) Valid(ctx context.Context) validator.Evaluator {
+	var eval validator.Evaluator = make(validator.Evaluator)
+
+	eval.CheckField(validator.NotBlank(c.Reason), "reason", "reason can not be empty")
+	eval.CheckField(validator.MaxChar(c.Reason, 255), "reason", "reason can not be longer than 255 characters")
+
+	return eval
+}
@@ -1,20 +1,57 @@
 package product
 
 import (
+	"cmp"
 	"context"
+	"github.com/JoaoRafa19/gobid/internal/money"
 	"github.com/JoaoRafa19/gobid/internal/validator"
 	"github.com/google/uuid"
 	"time"
 )
 
 type CreateProductRequest struct {
-	SellerID    uuid.UUID `json:"seller_id"`
-	ProductName string    `json:"product_name"`
-	Description string    `json:"description"`
-	BasePrice   float64   `json:"base_price"`
-	AuctionEnd  time.Time `json:"auction_end"`
+	SellerID    uuid.UUID    `json:"seller_id"`
+	ProductName string       `json:"product_name"`
+	Description string       `json:"description"`
+	BasePrice   money.Amount `json:"base_price"`
+	AuctionEnd  time.Time    `json:"auction_end"`
+
+	// Currency defaults to BRL, every price and bid of the auction is in it.
+	Currency string `json:"currency"`
+
+	// AuctionStart is optional, an auction without it starts right away.
+	AuctionStart time.Time `json:"auction_start"`
+
+	SoftCloseMinutes int32 `json:"soft_close_minutes"`
+	ExtensionMinutes int32 `json:"extension_minutes"`
+
+	ReservePrice money.Amount `json:"reserve_price"`
+	BuyNowPrice  money.Amount `json:"buy_now_price"`
+	BidIncrement money.Amount `json:"bid_increment"`
+
+	// AuctionType defaults to english. Dutch auctions start at BasePrice and
+	// lower it by PriceDrop every PriceDropSeconds until PriceFloor is reached.
+	// Sealed auctions hide every bid until the end and take BasePrice as the
+	// lowest accepted bid.
+	AuctionType      string       `json:"auction_type"`
+	PriceFloor       money.Amount `json:"price_floor"`
+	PriceDrop        money.Amount `json:"price_drop"`
+	PriceDropSeconds int32        `json:"price_drop_seconds"`
 }
 
+const (
+	AuctionTypeEnglish           = "english"
+	AuctionTypeDutch             = "dutch"
+	AuctionTypeSealedFirstPrice  = "sealed_first_price"
+	AuctionTypeSealedSecondPrice = "sealed_second_price"
+)
+
+const (
+	CurrencyBRL = "BRL"
+	CurrencyUSD = "USD"
+	CurrencyEUR = "EUR"
+)
+
 const minAuctionDuration = time.Hour * 2
 
 func (c CreateProductRequest) Valid(ctx context.Context) validator.Evaluator {
@@ -28,7 +65,85 @@ func (c CreateProductRequest) Valid(ctx context.Context) validator.Evaluator {
 		"description requires length between 10 and 255 ",
 	)
 	eval.CheckField(c.BasePrice > 0, "base_price", "base price must be greater than 0")
-	eval.CheckField(c.AuctionEnd.Sub(time.Now()) >= minAuctionDuration, "auction_end", "auction time must have at least 2 hours")
+	eval.CheckField(
+		validator.PermittedValue(cmp.Or(c.Currency, CurrencyBRL), CurrencyBRL, CurrencyUSD, CurrencyEUR),
+		"currency",
+		"currency must be BRL, USD or EUR",
+	)
+	eval.CheckField(
+		c.ReservePrice == 0 || c.ReservePrice >= c.BasePrice,
+		"reserve_price",
+		"reserve price must not be lower than the base price",
+	)
+	eval.CheckField(
+		c.BuyNowPrice == 0 || (c.BuyNowPrice > c.BasePrice && c.BuyNowPrice >= c.ReservePrice),
+		"buy_now_price",
+		"buy now price must be greater than the base price and not lower than the reserve price",
+	)
+	eval.CheckField(c.BidIncrement >= 0, "bid_increment", "bid increment can not be negative")
+	auctionStart := c.StartsAt()
+	eval.CheckField(
+		c.AuctionStart.IsZero() || c.AuctionStart.After(time.Now()),
+		"auction_start",
+		"auction start can not be in the past",
+	)
+	eval.CheckField(c.AuctionEnd.After(auctionStart), "auction_end", "auction end must be after the auction start")
+	eval.CheckField(c.AuctionEnd.Sub(auctionStart) >= minAuctionDuration, "auction_end", "auction time must have at least 2 hours")
+	eval.CheckField(c.SoftCloseMinutes >= 0, "soft_close_minutes", "soft close minutes can not be negative")
+	eval.CheckField(c.ExtensionMinutes >= 0, "extension_minutes", "extension minutes can not be negative")
+	eval.CheckField(
+		(c.SoftCloseMinutes == 0) == (c.ExtensionMinutes == 0),
+		"extension_minutes",
+		"soft close minutes and extension minutes must be set together",
+	)
+
+	auctionType := cmp.Or(c.AuctionType, AuctionTypeEnglish)
+	eval.CheckField(
+		validator.PermittedValue(
+			auctionType,
+			AuctionTypeEnglish,
+			AuctionTypeDutch,
+			AuctionTypeSealedFirstPrice,
+			AuctionTypeSealedSecondPrice,
+		),
+		"auction_type",
+		"auction type must be english, dutch, sealed_first_price or sealed_second_price",
+	)
+	if auctionType == AuctionTypeDutch {
+		eval.CheckField(c.PriceDrop > 0, "price_drop", "price drop must be greater than 0")
+		eval.CheckField(c.PriceDropSeconds > 0, "price_drop_seconds", "price drop seconds must be greater than 0")
+		eval.CheckField(
+			c.PriceFloor >= 0 && c.PriceFloor < c.BasePrice,
+			"price_floor",
+			"price floor must be lower than the base price",
+		)
+		eval.CheckField(
+			c.ReservePrice == 0 && c.BuyNowPrice == 0 && c.BidIncrement == 0 && c.SoftCloseMinutes == 0,
+			"auction_type",
+			"dutch auctions do not support reserve price, buy now price, bid increment or soft close",
+		)
+	} else {
+		eval.CheckField(
+			c.PriceFloor == 0 && c.PriceDrop == 0 && c.PriceDropSeconds == 0,
+			"auction_type",
+			"price floor and price drop are only available for dutch auctions",
+		)
+	}
+	if auctionType == AuctionTypeSealedFirstPrice || auctionType == AuctionTypeSealedSecondPrice {
+		eval.CheckField(
+			c.BuyNowPrice == 0 && c.BidIncrement == 0 && c.SoftCloseMinutes == 0,
+			"auction_type",
+			"sealed auctions do not support buy now price, bid increment or soft close",
+		)
+	}
 
 	return eval
 }
+
+// StartsAt is when the auction opens, now when no start time was requested.
+func (c CreateProductRequest) StartsAt() time.Time {
+	if c.AuctionStart.IsZero() {
+		return time.Now()
+	}
+	return c.AuctionStart
+}
@@ -0,0 +1,29 @@
+package product
+
+import (
+	"context"
+	"github.com/JoaoRafa19/gobid/internal/money"
+	"github.com/JoaoRafa19/gobid/internal/validator"
+)
+
+type PlaceBidRequest struct {
+	Amount    money.Amount `json:"amount"`
+	MaxAmount money.Amount `json:"max_amount"`
+
+	// Currency defaults to the auction currency.
+	Currency string `json:"currency"`
+}
+
+func (c PlaceBidRequest) Valid(ctx context.Context) validator.Evaluator {
+	var eval validator.Evaluator = make(validator.Evaluator)
+
+	eval.CheckField(c.Amount > 0, "amount", "amount must be greater than zero")
+	eval.CheckField(c.MaxAmount >= 0, "max_amount", "max amount can not be negative")
+	eval.CheckField(
+		c.Currency == "" || validator.PermittedValue(c.Currency, CurrencyBRL, CurrencyUSD, CurrencyEUR),
+		"currency",
+		"currency must be one of BRL, USD or EUR",
+	)
+
+	return eval
+}
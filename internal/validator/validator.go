@@ -3,6 +3,7 @@ package validator
 import (
 	"context"
 	"regexp"
+	"slices"
 	"strings"
 	"unicode/utf8"
 )
@@ -46,3 +47,7 @@ func MinChar(s string, min int) bool {
 func Matches(s string, regex *regexp.Regexp) bool {
 	return regex.MatchString(s)
 }
+
+func PermittedValue[T comparable](value T, permittedValues ...T) bool {
+	return slices.Contains(permittedValues, value)
+}